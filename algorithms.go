@@ -2,16 +2,12 @@ package gdal
 
 /*
 #include "go_gdal.h"
-#include "gdal_version.h"
-
-#cgo darwin pkg-config: gdal
-#cgo linux LDFLAGS: -L/usr/local/lib -lgdal
-#cgo linux CFLAGS: -I/usr/local/include
-#cgo windows LDFLAGS: -LC:/Python27/Lib/site-packages/osgeo/lib -lgdal_i
-#cgo windows CFLAGS: -IC:/Python27/Lib/site-packages/osgeo/include/gdal
 */
 import "C"
 import (
+	"context"
+	"fmt"
+	"runtime"
 	"unsafe"
 )
 
@@ -27,9 +23,12 @@ func ComputeMedianCutPCT(
 	progress ProgressFunc,
 	data interface{},
 ) int {
-	arg := &goGDALProgressFuncProxyArgs{
-		progress, data,
-	}
+	defer runtime.KeepAlive(red.dataset)
+	defer runtime.KeepAlive(green.dataset)
+	defer runtime.KeepAlive(blue.dataset)
+	defer runtime.KeepAlive(ct.handle)
+	arg := newProgressArg(progress, data)
+	defer arg.release()
 
 	err := C.GDALComputeMedianCutPCT(
 		red.cval,
@@ -38,8 +37,8 @@ func ComputeMedianCutPCT(
 		nil,
 		C.int(colors),
 		ct.cval,
-		C.goGDALProgressFuncProxyB(),
-		unsafe.Pointer(arg),
+		arg.fn(),
+		arg.ptr(),
 	)
 	return int(err)
 }
@@ -51,9 +50,13 @@ func DitherRGB2PCT(
 	progress ProgressFunc,
 	data interface{},
 ) int {
-	arg := &goGDALProgressFuncProxyArgs{
-		progress, data,
-	}
+	defer runtime.KeepAlive(red.dataset)
+	defer runtime.KeepAlive(green.dataset)
+	defer runtime.KeepAlive(blue.dataset)
+	defer runtime.KeepAlive(target.dataset)
+	defer runtime.KeepAlive(ct.handle)
+	arg := newProgressArg(progress, data)
+	defer arg.release()
 
 	err := C.GDALDitherRGB2PCT(
 		red.cval,
@@ -61,14 +64,15 @@ func DitherRGB2PCT(
 		blue.cval,
 		target.cval,
 		ct.cval,
-		C.goGDALProgressFuncProxyB(),
-		unsafe.Pointer(arg),
+		arg.fn(),
+		arg.ptr(),
 	)
 	return int(err)
 }
 
 // Compute checksum for image region
 func (rb RasterBand) Checksum(xOff, yOff, xSize, ySize int) int {
+	defer runtime.KeepAlive(rb.dataset)
 	sum := C.GDALChecksumImage(rb.cval, C.int(xOff), C.int(yOff), C.int(xSize), C.int(ySize))
 	return int(sum)
 }
@@ -80,9 +84,11 @@ func (src RasterBand) ComputeProximity(
 	progress ProgressFunc,
 	data interface{},
 ) error {
-	arg := &goGDALProgressFuncProxyArgs{
-		progress, data,
-	}
+	defer runtime.KeepAlive(src.dataset)
+	defer runtime.KeepAlive(dest.dataset)
+	defer pinErrorState()()
+	arg := newProgressArg(progress, data)
+	defer arg.release()
 
 	length := len(options)
 	opts := make([]*C.char, length+1)
@@ -96,11 +102,24 @@ func (src RasterBand) ComputeProximity(
 		src.cval,
 		dest.cval,
 		(**C.char)(unsafe.Pointer(&opts[0])),
-		C.goGDALProgressFuncProxyB(),
-		unsafe.Pointer(arg),
+		arg.fn(),
+		arg.ptr(),
 	).Err()
 }
 
+// Compute the proximity of all pixels in the image, aborting when ctx is done
+func (src RasterBand) ComputeProximityContext(
+	ctx context.Context,
+	dest RasterBand,
+	options []string,
+	progress ProgressFunc,
+	data interface{},
+) error {
+	cp := newContextProgress(ctx, progress)
+	err := src.ComputeProximity(dest, options, cp.fn, data)
+	return cp.err(err)
+}
+
 // Fill selected raster regions by interpolation from the edges
 func (src RasterBand) FillNoData(
 	mask RasterBand,
@@ -110,9 +129,11 @@ func (src RasterBand) FillNoData(
 	progress ProgressFunc,
 	data interface{},
 ) error {
-	arg := &goGDALProgressFuncProxyArgs{
-		progress, data,
-	}
+	defer runtime.KeepAlive(src.dataset)
+	defer runtime.KeepAlive(mask.dataset)
+	defer pinErrorState()()
+	arg := newProgressArg(progress, data)
+	defer arg.release()
 
 	length := len(options)
 	opts := make([]*C.char, length+1)
@@ -129,11 +150,29 @@ func (src RasterBand) FillNoData(
 		0,
 		C.int(iterations),
 		(**C.char)(unsafe.Pointer(&opts[0])),
-		C.goGDALProgressFuncProxyB(),
-		unsafe.Pointer(arg),
+		arg.fn(),
+		arg.ptr(),
 	).Err()
 }
 
+// Fill selected raster regions by interpolation, aborting when ctx is done
+func (src RasterBand) FillNoDataContext(
+	ctx context.Context,
+	mask RasterBand,
+	distance float64,
+	iterations int,
+	options []string,
+	progress ProgressFunc,
+	data interface{},
+) error {
+	cp := newContextProgress(ctx, progress)
+	err := src.FillNoData(
+		mask, distance, iterations, options,
+		cp.fn, data,
+	)
+	return cp.err(err)
+}
+
 // Create polygon coverage from raster data using an integer buffer
 func (src RasterBand) Polygonize(
 	mask RasterBand,
@@ -143,9 +182,12 @@ func (src RasterBand) Polygonize(
 	progress ProgressFunc,
 	data interface{},
 ) error {
-	arg := &goGDALProgressFuncProxyArgs{
-		progress, data,
-	}
+	defer runtime.KeepAlive(src.dataset)
+	defer runtime.KeepAlive(mask.dataset)
+	defer runtime.KeepAlive(layer.dataSource)
+	defer pinErrorState()()
+	arg := newProgressArg(progress, data)
+	defer arg.release()
 
 	length := len(options)
 	opts := make([]*C.char, length+1)
@@ -161,11 +203,29 @@ func (src RasterBand) Polygonize(
 		layer.cval,
 		C.int(fieldIndex),
 		(**C.char)(unsafe.Pointer(&opts[0])),
-		C.goGDALProgressFuncProxyB(),
-		unsafe.Pointer(arg),
+		arg.fn(),
+		arg.ptr(),
 	).Err()
 }
 
+// Create polygon coverage using an integer buffer, aborting when ctx is done
+func (src RasterBand) PolygonizeContext(
+	ctx context.Context,
+	mask RasterBand,
+	layer Layer,
+	fieldIndex int,
+	options []string,
+	progress ProgressFunc,
+	data interface{},
+) error {
+	cp := newContextProgress(ctx, progress)
+	err := src.Polygonize(
+		mask, layer, fieldIndex, options,
+		cp.fn, data,
+	)
+	return cp.err(err)
+}
+
 // Create polygon coverage from raster data using a floating point buffer
 func (src RasterBand) FPolygonize(
 	mask RasterBand,
@@ -175,9 +235,12 @@ func (src RasterBand) FPolygonize(
 	progress ProgressFunc,
 	data interface{},
 ) error {
-	arg := &goGDALProgressFuncProxyArgs{
-		progress, data,
-	}
+	defer runtime.KeepAlive(src.dataset)
+	defer runtime.KeepAlive(mask.dataset)
+	defer runtime.KeepAlive(layer.dataSource)
+	defer pinErrorState()()
+	arg := newProgressArg(progress, data)
+	defer arg.release()
 
 	length := len(options)
 	opts := make([]*C.char, length+1)
@@ -193,11 +256,29 @@ func (src RasterBand) FPolygonize(
 		layer.cval,
 		C.int(fieldIndex),
 		(**C.char)(unsafe.Pointer(&opts[0])),
-		C.goGDALProgressFuncProxyB(),
-		unsafe.Pointer(arg),
+		arg.fn(),
+		arg.ptr(),
 	).Err()
 }
 
+// Create polygon coverage using a floating point buffer, aborting when ctx is done
+func (src RasterBand) FPolygonizeContext(
+	ctx context.Context,
+	mask RasterBand,
+	layer Layer,
+	fieldIndex int,
+	options []string,
+	progress ProgressFunc,
+	data interface{},
+) error {
+	cp := newContextProgress(ctx, progress)
+	err := src.FPolygonize(
+		mask, layer, fieldIndex, options,
+		cp.fn, data,
+	)
+	return cp.err(err)
+}
+
 // Removes small raster polygons
 func (src RasterBand) SieveFilter(
 	mask, dest RasterBand,
@@ -206,9 +287,12 @@ func (src RasterBand) SieveFilter(
 	progress ProgressFunc,
 	data interface{},
 ) error {
-	arg := &goGDALProgressFuncProxyArgs{
-		progress, data,
-	}
+	defer runtime.KeepAlive(src.dataset)
+	defer runtime.KeepAlive(mask.dataset)
+	defer runtime.KeepAlive(dest.dataset)
+	defer pinErrorState()()
+	arg := newProgressArg(progress, data)
+	defer arg.release()
 
 	length := len(options)
 	opts := make([]*C.char, length+1)
@@ -225,11 +309,28 @@ func (src RasterBand) SieveFilter(
 		C.int(threshold),
 		C.int(connectedness),
 		(**C.char)(unsafe.Pointer(&opts[0])),
-		C.goGDALProgressFuncProxyB(),
-		unsafe.Pointer(arg),
+		arg.fn(),
+		arg.ptr(),
 	).Err()
 }
 
+// Remove small raster polygons, aborting when ctx is done
+func (src RasterBand) SieveFilterContext(
+	ctx context.Context,
+	mask, dest RasterBand,
+	threshold, connectedness int,
+	options []string,
+	progress ProgressFunc,
+	data interface{},
+) error {
+	cp := newContextProgress(ctx, progress)
+	err := src.SieveFilter(
+		mask, dest, threshold, connectedness, options,
+		cp.fn, data,
+	)
+	return cp.err(err)
+}
+
 /* --------------------------------------------- */
 /* Warp functions                                */
 /* --------------------------------------------- */
@@ -287,8 +388,59 @@ func (src RasterBand) SieveFilter(
 /* Rasterizer functions                          */
 /* --------------------------------------------- */
 
-// Burn geometries into raster
-//Unimplmemented: RasterizeGeometries
+// Burn geometries into the listed bands of a raster. The geometries are in
+// the georeferenced coordinates of the dataset and burnValues holds one
+// value per band for each geometry, in geometry order.
+func (dataset Dataset) RasterizeGeometries(
+	bandList []int,
+	geometries []Geometry,
+	burnValues []float64,
+	options []string,
+	progress ProgressFunc,
+	data interface{},
+) error {
+	defer runtime.KeepAlive(dataset.handle)
+	defer runtime.KeepAlive(geometries)
+	if len(bandList) == 0 || len(geometries) == 0 {
+		return fmt.Errorf("no bands or geometries to rasterize")
+	}
+	if len(burnValues) != len(bandList)*len(geometries) {
+		return fmt.Errorf(
+			"got %d burn values, %d bands and %d geometries need %d",
+			len(burnValues), len(bandList), len(geometries),
+			len(bandList)*len(geometries),
+		)
+	}
+	defer pinErrorState()()
+	arg := newProgressArg(progress, data)
+	defer arg.release()
+
+	cBands := make([]C.int, len(bandList))
+	for i, band := range bandList {
+		cBands[i] = C.int(band)
+	}
+	cGeoms := make([]C.OGRGeometryH, len(geometries))
+	for i, geom := range geometries {
+		cGeoms[i] = geom.cval
+	}
+	cValues := make([]C.double, len(burnValues))
+	for i, value := range burnValues {
+		cValues[i] = C.double(value)
+	}
+	opts, free := cStringList(options)
+	defer free()
+
+	return C.GDALRasterizeGeometries(
+		dataset.cval,
+		C.int(len(cBands)), &cBands[0],
+		C.int(len(cGeoms)), &cGeoms[0],
+		nil, nil,
+		&cValues[0],
+		opts,
+		arg.fn(),
+		arg.ptr(),
+	).Err()
+}
 
 // Burn geometries from the specified list of layers into the raster
 //Unimplemented: RasterizeLayers
@@ -0,0 +1,216 @@
+package gdal
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+/* --------------------------------------------- */
+/* Archives                                      */
+/* --------------------------------------------- */
+
+// Extensions of files that accompany a dataset in an archive but are not
+// datasets themselves
+var archiveSidecars = map[string]bool{
+	".aux": true, ".cpg": true, ".dbf": true, ".idx": true, ".lyr": true,
+	".md": true, ".ovr": true, ".prj": true, ".qix": true, ".qmd": true,
+	".qml": true, ".sbn": true, ".sbx": true, ".shx": true, ".tfw": true,
+	".tifw": true, ".txt": true, ".wld": true, ".xml": true, ".jgw": true,
+	".pgw": true, ".rrd": true,
+}
+
+// archivePrefix returns the VSI prefix for reading an archive, from its
+// extension or, failing that, from its first bytes
+func archivePrefix(archive string) (string, error) {
+	lower := strings.ToLower(archive)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return "/vsizip/", nil
+	case strings.HasSuffix(lower, ".tar"), strings.HasSuffix(lower, ".tar.gz"),
+		strings.HasSuffix(lower, ".tgz"):
+		return "/vsitar/", nil
+	case strings.HasSuffix(lower, ".gz"):
+		return "/vsigzip/", nil
+	}
+
+	file, err := VSIOpen(archive)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	header := make([]byte, 262)
+	n, _ := io.ReadFull(file, header)
+	header = header[:n]
+	switch {
+	case bytes.HasPrefix(header, []byte("PK\x03\x04")):
+		return "/vsizip/", nil
+	case bytes.HasPrefix(header, []byte("\x1f\x8b")):
+		return "/vsigzip/", nil
+	case len(header) >= 262 && string(header[257:262]) == "ustar":
+		return "/vsitar/", nil
+	}
+	return "", fmt.Errorf("%s is not a zip, tar or gzip archive", archive)
+}
+
+// Return true if the file is a zip, tar or gzip archive
+func IsArchive(archive string) bool {
+	_, err := archivePrefix(archive)
+	return err == nil
+}
+
+// Build the VSI path of a member of an archive, such as
+// "/vsizip/data.zip/roads/roads.shp". A gzip file has a single member
+// which is named by an empty string.
+func ArchivePath(archive, member string) (string, error) {
+	prefix, err := archivePrefix(archive)
+	if err != nil {
+		return "", err
+	}
+	if prefix == "/vsigzip/" {
+		if member != "" {
+			return "", fmt.Errorf("gzip file %s has no member %s", archive, member)
+		}
+		return prefix + archive, nil
+	}
+	// Joining with path.Join would turn "/vsizip//abs/a.zip" into a
+	// relative archive name
+	member = strings.Trim(path.Clean("/"+member), "/")
+	if member == "" {
+		return prefix + archive, nil
+	}
+	return prefix + archive + "/" + member, nil
+}
+
+// List the files of an archive as slash separated paths, in sorted order.
+// Directories are not listed. A gzip file has a single member, "".
+func ArchiveMembers(archive string) ([]string, error) {
+	root, err := ArchivePath(archive, "")
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(root, "/vsigzip/") {
+		return []string{""}, nil
+	}
+
+	var members []string
+	var walk func(dir string) error
+	walk = func(dir string) error {
+		names, err := VSIReadDir(strings.TrimSuffix(root+"/"+dir, "/"))
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			member := path.Join(dir, name)
+			stat, err := VSIStatL(root + "/" + member)
+			if err != nil {
+				return err
+			}
+			if stat.IsDir() {
+				if err := walk(member); err != nil {
+					return err
+				}
+				continue
+			}
+			members = append(members, member)
+		}
+		return nil
+	}
+	if err := walk(""); err != nil {
+		return nil, err
+	}
+	sort.Strings(members)
+	return members, nil
+}
+
+// Return the VSI path of the only dataset in an archive, skipping sidecar
+// files such as the .dbf and .shx of a shapefile, hidden files and macOS
+// resource forks. It is an error for the archive to hold no dataset or
+// more than one; pick a member with ArchivePath in that case.
+func ArchiveDatasetPath(archive string) (string, error) {
+	members, err := ArchiveMembers(archive)
+	if err != nil {
+		return "", err
+	}
+	var datasets []string
+	for _, member := range members {
+		base := path.Base(member)
+		if strings.HasPrefix(member, "__MACOSX/") || strings.HasPrefix(base, ".") {
+			continue
+		}
+		if archiveSidecars[strings.ToLower(path.Ext(base))] {
+			continue
+		}
+		datasets = append(datasets, member)
+	}
+	switch len(datasets) {
+	case 0:
+		return "", fmt.Errorf("archive %s holds no dataset", archive)
+	case 1:
+		return ArchivePath(archive, datasets[0])
+	}
+	return "", fmt.Errorf(
+		"archive %s holds %d datasets: %s",
+		archive, len(datasets), strings.Join(datasets, ", "),
+	)
+}
+
+// Open the only raster dataset in an archive
+func OpenArchive(archive string, access Access) (Dataset, error) {
+	name, err := ArchiveDatasetPath(archive)
+	if err != nil {
+		return Dataset{}, err
+	}
+	return Open(name, access)
+}
+
+// Open the only vector data source in an archive
+func OpenArchiveDataSource(archive string, update int) (DataSource, error) {
+	name, err := ArchiveDatasetPath(archive)
+	if err != nil {
+		return DataSource{}, err
+	}
+	return OpenDataSource(name, update)
+}
+
+// Create a zip archive holding copies of files at its top level, such as
+// the FileList of a shapefile. The files may be on disk or in any readable
+// VSI file system.
+func WriteZip(zipName string, files []string) error {
+	seen := make(map[string]bool, len(files))
+	for _, file := range files {
+		base := filepath.Base(file)
+		if seen[base] {
+			return fmt.Errorf("%s appears twice in the file list", base)
+		}
+		seen[base] = true
+	}
+
+	for _, file := range files {
+		if err := copyVSIFile("/vsizip/"+zipName+"/"+filepath.Base(file), file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyVSIFile(dst, src string) error {
+	in, err := VSIOpen(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := VSICreate(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
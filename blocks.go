@@ -0,0 +1,293 @@
+package gdal
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"runtime"
+	"sync"
+)
+
+/* --------------------------------------------- */
+/* Block iteration                               */
+/* --------------------------------------------- */
+
+// Window is a rectangular region of a raster in pixel coordinates
+type Window struct {
+	XOff, YOff, XSize, YSize int
+}
+
+// BlockCount returns the number of blocks along each axis of the band
+func (rasterBand RasterBand) BlockCount() (int, int) {
+	blockX, blockY := rasterBand.BlockSize()
+	if blockX <= 0 || blockY <= 0 {
+		return 0, 0
+	}
+	return (rasterBand.XSize() + blockX - 1) / blockX,
+		(rasterBand.YSize() + blockY - 1) / blockY
+}
+
+// ActualBlockSize returns the number of valid pixels in a block, which is
+// smaller than BlockSize for blocks on the right and bottom edges.
+// It is computed in Go since GDALGetActualBlockSize requires GDAL 2.2.
+func (rasterBand RasterBand) ActualBlockSize(xBlock, yBlock int) (int, int, error) {
+	if err := rasterBand.checkBlock(xBlock, yBlock); err != nil {
+		return 0, 0, err
+	}
+	w := rasterBand.blockWindow(xBlock, yBlock)
+	return w.XSize, w.YSize, nil
+}
+
+// blockWindow returns the valid pixel window of a block
+func (rasterBand RasterBand) blockWindow(xBlock, yBlock int) Window {
+	blockX, blockY := rasterBand.BlockSize()
+	w := Window{XOff: xBlock * blockX, YOff: yBlock * blockY, XSize: blockX, YSize: blockY}
+	w.XSize = minInt(w.XSize, rasterBand.XSize()-w.XOff)
+	w.YSize = minInt(w.YSize, rasterBand.YSize()-w.YOff)
+	return w
+}
+
+// The builtin min and max are shadowed by the C.double versions in ogr.go
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// Block is one block of a band as yielded by Blocks
+type Block[T Numeric] struct {
+	// Block index
+	XBlock, YBlock int
+	// Valid pixels of the block
+	Window Window
+	// Pixels held in Data: Window grown by the halo, clipped to the raster
+	DataWindow Window
+	// Pixel values of DataWindow in row-major order. The slice is reused
+	// between blocks and must be copied if retained.
+	Data []T
+}
+
+// At returns the value of the pixel at raster coordinates x, y, which must
+// lie inside DataWindow
+func (block *Block[T]) At(x, y int) T {
+	w := block.DataWindow
+	return block.Data[(y-w.YOff)*w.XSize+(x-w.XOff)]
+}
+
+// Blocks iterates over the blocks of a band in row-major order, reading each
+// block plus halo pixels on every side into a reused buffer. Iteration stops
+// after the first error.
+func Blocks[T Numeric](rasterBand RasterBand, halo int) iter.Seq2[*Block[T], error] {
+	return func(yield func(*Block[T], error) bool) {
+		if halo < 0 {
+			yield(nil, fmt.Errorf("negative halo %d", halo))
+			return
+		}
+		blockX, blockY := rasterBand.BlockSize()
+		countX, countY := rasterBand.BlockCount()
+		if countX == 0 || countY == 0 {
+			yield(nil, fmt.Errorf("invalid block size %dx%d", blockX, blockY))
+			return
+		}
+		xSize, ySize := rasterBand.XSize(), rasterBand.YSize()
+		buf := make([]T, (blockX+2*halo)*(blockY+2*halo))
+		block := &Block[T]{}
+
+		for yBlock := 0; yBlock < countY; yBlock++ {
+			for xBlock := 0; xBlock < countX; xBlock++ {
+				w := rasterBand.blockWindow(xBlock, yBlock)
+				x0, y0 := maxInt(w.XOff-halo, 0), maxInt(w.YOff-halo, 0)
+				x1 := minInt(w.XOff+w.XSize+halo, xSize)
+				y1 := minInt(w.YOff+w.YSize+halo, ySize)
+				dw := Window{XOff: x0, YOff: y0, XSize: x1 - x0, YSize: y1 - y0}
+
+				*block = Block[T]{
+					XBlock:     xBlock,
+					YBlock:     yBlock,
+					Window:     w,
+					DataWindow: dw,
+					Data:       buf[:dw.XSize*dw.YSize],
+				}
+				err := ReadRegion(
+					rasterBand,
+					dw.XOff, dw.YOff, dw.XSize, dw.YSize,
+					block.Data,
+					dw.XSize, dw.YSize,
+				)
+				if err != nil {
+					yield(nil, err)
+					return
+				}
+				if !yield(block, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+/* --------------------------------------------- */
+/* Parallel block processing                     */
+/* --------------------------------------------- */
+
+// BlockFunc computes one block of output. in holds one slice per source
+// band and out has the same length; both cover window in row-major order
+// and are reused between calls.
+type BlockFunc[T Numeric] func(window Window, in [][]T, out []T) error
+
+type blockJob[T Numeric] struct {
+	seq    int
+	window Window
+	in     [][]T
+	out    []T
+	err    error
+}
+
+// ProcessBlocks applies fn to every block of dst, reading the matching
+// windows of src and writing the result back to dst. All GDAL calls are
+// made from the calling goroutine, one block at a time and in block order;
+// only fn runs on the workers. Windows follow the block layout of dst.
+// Whole blocks of bands holding T with the block size of dst go through
+// ReadBlock and WriteBlock; edge blocks and other bands go through RasterIO,
+// which converts between T and the band data type. The first error from
+// fn, GDAL or ctx stops processing and is returned.
+func ProcessBlocks[T Numeric](
+	ctx context.Context,
+	src []RasterBand,
+	dst RasterBand,
+	workers int,
+	fn BlockFunc[T],
+) error {
+	xSize, ySize := dst.XSize(), dst.YSize()
+	for i, band := range src {
+		if band.XSize() != xSize || band.YSize() != ySize {
+			return fmt.Errorf(
+				"source band %d is %dx%d, destination is %dx%d",
+				i, band.XSize(), band.YSize(), xSize, ySize,
+			)
+		}
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	var windows []Window
+	countX, countY := dst.BlockCount()
+	for yBlock := 0; yBlock < countY; yBlock++ {
+		for xBlock := 0; xBlock < countX; xBlock++ {
+			windows = append(windows, dst.blockWindow(xBlock, yBlock))
+		}
+	}
+	if len(windows) == 0 {
+		return fmt.Errorf("destination band has no blocks")
+	}
+
+	// At most inFlight jobs exist, so neither channel send ever blocks.
+	inFlight := 2 * workers
+	blockX, blockY := dst.BlockSize()
+	// WriteBlock bypasses the block cache, so write out any dirty cached
+	// blocks of dst first rather than have them overwrite the results.
+	dst.FlushCache()
+	free := make([]*blockJob[T], inFlight)
+	for i := range free {
+		job := &blockJob[T]{in: make([][]T, len(src)), out: make([]T, blockX*blockY)}
+		for j := range job.in {
+			job.in[j] = make([]T, blockX*blockY)
+		}
+		free[i] = job
+	}
+
+	workCtx, cancel := context.WithCancel(ctx)
+	jobs := make(chan *blockJob[T], inFlight)
+	results := make(chan *blockJob[T], inFlight)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				if job.err = workCtx.Err(); job.err == nil {
+					job.err = fn(job.window, job.in, job.out)
+				}
+				results <- job
+			}
+		}()
+	}
+	defer func() {
+		cancel()
+		close(jobs)
+		wg.Wait()
+	}()
+
+	pending := make(map[int]*blockJob[T])
+	sent, next := 0, 0
+	for next < len(windows) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if sent < len(windows) && len(free) > 0 {
+			job := free[len(free)-1]
+			free = free[:len(free)-1]
+			w := windows[sent]
+			n := w.XSize * w.YSize
+			job.seq, job.window, job.err = sent, w, nil
+			job.out = job.out[:n]
+			for i, band := range src {
+				job.in[i] = job.in[i][:n]
+				if err := blockIO(band, Read, w, blockX, blockY, job.in[i]); err != nil {
+					return err
+				}
+			}
+			jobs <- job
+			sent++
+			continue
+		}
+
+		select {
+		case job := <-results:
+			pending[job.seq] = job
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		for job, ok := pending[next]; ok; job, ok = pending[next] {
+			delete(pending, next)
+			if job.err != nil {
+				return job.err
+			}
+			if err := blockIO(dst, Write, job.window, blockX, blockY, job.out); err != nil {
+				return err
+			}
+			free = append(free, job)
+			next++
+		}
+	}
+
+	dst.FlushCache()
+	return nil
+}
+
+// blockIO reads or writes window w, one block of a blockX x blockY layout,
+// through ReadBlock/WriteBlock when it is a whole natural block of a band
+// holding T, and through RasterIO otherwise.
+func blockIO[T Numeric](rasterBand RasterBand, rwFlag RWFlag, w Window, blockX, blockY int, buf []T) error {
+	bandX, bandY := rasterBand.BlockSize()
+	whole := w.XSize == blockX && w.YSize == blockY && bandX == blockX && bandY == blockY
+	if whole && DataTypeOf[T]() == rasterBand.RasterDataType() {
+		xBlock, yBlock := w.XOff/blockX, w.YOff/blockY
+		if rwFlag == Read {
+			return ReadBlockSlice(rasterBand, xBlock, yBlock, buf)
+		}
+		return WriteBlockSlice(rasterBand, xBlock, yBlock, buf)
+	}
+	return regionIO(rasterBand, rwFlag, w.XOff, w.YOff, w.XSize, w.YSize, buf, w.XSize, w.YSize)
+}
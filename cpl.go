@@ -2,16 +2,14 @@ package gdal
 
 /*
 #include "go_gdal.h"
-#include "gdal_version.h"
-
-#cgo darwin pkg-config: gdal
-#cgo linux LDFLAGS: -L/usr/local/lib -lgdal
-#cgo linux CFLAGS: -I/usr/local/include
-#cgo windows LDFLAGS: -LC:/Python27/Lib/site-packages/osgeo/lib -lgdal_i
-#cgo windows CFLAGS: -IC:/Python27/Lib/site-packages/osgeo/include/gdal
 */
 import "C"
 import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"sync/atomic"
 	"unsafe"
 )
 
@@ -33,3 +31,191 @@ func SetConfigOption(key, value string) {
 	defer C.free(unsafe.Pointer(v))
 	C.CPLSetConfigOption(k, v)
 }
+
+/* -------------------------------------------------------------------- */
+/*      Error handling.                                                 */
+/* -------------------------------------------------------------------- */
+
+// Error class of a CPL error
+type CPLErr int
+
+const (
+	CE_None    = CPLErr(C.CE_None)
+	CE_Debug   = CPLErr(C.CE_Debug)
+	CE_Warning = CPLErr(C.CE_Warning)
+	CE_Failure = CPLErr(C.CE_Failure)
+	CE_Fatal   = CPLErr(C.CE_Fatal)
+)
+
+// Fetch the name of this error class
+func (class CPLErr) Name() string {
+	switch class {
+	case CE_None:
+		return "None"
+	case CE_Debug:
+		return "Debug"
+	case CE_Warning:
+		return "Warning"
+	case CE_Failure:
+		return "Failure"
+	case CE_Fatal:
+		return "Fatal"
+	}
+	return fmt.Sprintf("CPLErr(%d)", int(class))
+}
+
+// Return the sentinel error matching this class
+func (class CPLErr) sentinel() error {
+	switch class {
+	case CE_Debug:
+		return ErrDebug
+	case CE_Warning:
+		return ErrWarning
+	case CE_Failure:
+		return ErrFailure
+	case CE_Fatal:
+		return ErrFatal
+	}
+	return ErrIllegal
+}
+
+// Error number of a CPL error
+type CPLErrorNum int
+
+const (
+	CPLE_None            = CPLErrorNum(C.CPLE_None)
+	CPLE_AppDefined      = CPLErrorNum(C.CPLE_AppDefined)
+	CPLE_OutOfMemory     = CPLErrorNum(C.CPLE_OutOfMemory)
+	CPLE_FileIO          = CPLErrorNum(C.CPLE_FileIO)
+	CPLE_OpenFailed      = CPLErrorNum(C.CPLE_OpenFailed)
+	CPLE_IllegalArg      = CPLErrorNum(C.CPLE_IllegalArg)
+	CPLE_NotSupported    = CPLErrorNum(C.CPLE_NotSupported)
+	CPLE_AssertionFailed = CPLErrorNum(C.CPLE_AssertionFailed)
+	CPLE_NoWriteAccess   = CPLErrorNum(C.CPLE_NoWriteAccess)
+	CPLE_UserInterrupt   = CPLErrorNum(C.CPLE_UserInterrupt)
+	CPLE_ObjectNull      = CPLErrorNum(C.CPLE_ObjectNull)
+)
+
+// CPLError is returned by calls that fail inside GDAL.  It carries the class,
+// number and message GDAL recorded for the failure, and matches ErrDebug,
+// ErrWarning, ErrFailure or ErrFatal (according to its class) with errors.Is.
+// Errors numbered CPLE_NotSupported also match ErrNotSupported.
+type CPLError struct {
+	Class CPLErr
+	Num   CPLErrorNum
+	Msg   string
+}
+
+func (err *CPLError) Error() string {
+	if err.Msg == "" {
+		return err.Class.sentinel().Error()
+	}
+	return fmt.Sprintf("%s: %s", err.Class.sentinel(), err.Msg)
+}
+
+// Return the sentinel error matching the class of this error
+func (err *CPLError) Unwrap() error {
+	return err.Class.sentinel()
+}
+
+// Report whether target is ErrNotSupported and this error says so
+func (err *CPLError) Is(target error) bool {
+	return target == ErrNotSupported && err.Num == CPLE_NotSupported
+}
+
+// Pin the calling goroutine to its OS thread and clear the thread-local CPL
+// error state.  GDAL records errors per thread, so a wrapper must stay on one
+// thread from the GDAL call until its result has been turned into an error:
+//
+//	defer pinErrorState()()
+//	return C.GDALSomething(...).Err()
+func pinErrorState() func() {
+	runtime.LockOSThread()
+	C.CPLErrorReset()
+	return runtime.UnlockOSThread
+}
+
+// Build the error returned when GDAL hands back a NULL handle, preferring the
+// message GDAL recorded over the generic description.
+func nullHandleError(format string, args ...interface{}) error {
+	if CPLErr(C.CPLGetLastErrorType()) == CE_None {
+		return fmt.Errorf(format, args...)
+	}
+	return lastError(CE_Failure)
+}
+
+// Return the failure recorded by GDAL since pinErrorState, for calls that
+// do not report failure through their return value
+func recordedError() error {
+	if CPLErr(C.CPLGetLastErrorType()) < CE_Failure {
+		return nil
+	}
+	return lastError(CPLErr(C.CPLGetLastErrorType()))
+}
+
+// Build an error of the given class from the last error recorded by GDAL,
+// then reset the error state so it is not reported twice.
+func lastError(class CPLErr) *CPLError {
+	err := &CPLError{Class: class}
+	if CPLErr(C.CPLGetLastErrorType()) != CE_None {
+		err.Num = CPLErrorNum(C.CPLGetLastErrorNo())
+		err.Msg = C.GoString(C.CPLGetLastErrorMsg())
+	}
+	C.CPLErrorReset()
+	return err
+}
+
+/* -------------------------------------------------------------------- */
+/*      Error handler.                                                  */
+/* -------------------------------------------------------------------- */
+
+// ErrorHandler receives the errors, warnings and debug messages emitted by
+// GDAL.  It may be called from any thread and must not call SetErrorHandler.
+type ErrorHandler func(class CPLErr, num CPLErrorNum, msg string)
+
+var errorHandler atomic.Pointer[ErrorHandler]
+
+// Install a handler receiving every message GDAL emits, instead of printing
+// them to stderr.  Passing nil restores the default handler.  Debug messages
+// are only emitted when the CPL_DEBUG configuration option is set.
+//
+// Installing a handler does not change the errors returned by the wrapper.
+func SetErrorHandler(handler ErrorHandler) {
+	if handler == nil {
+		errorHandler.Store(nil)
+		C.CPLSetErrorHandler(C.CPLErrorHandler(C.CPLDefaultErrorHandler))
+		return
+	}
+	errorHandler.Store(&handler)
+	C.CPLSetErrorHandler(C.goCPLErrorHandlerProxyB())
+}
+
+// Return an ErrorHandler forwarding GDAL messages to logger.  Debug messages
+// are logged at debug level, warnings at warn level and failures at error level.
+func SlogErrorHandler(logger *slog.Logger) ErrorHandler {
+	return func(class CPLErr, num CPLErrorNum, msg string) {
+		level := slog.LevelError
+		switch class {
+		case CE_None:
+			level = slog.LevelInfo
+		case CE_Debug:
+			level = slog.LevelDebug
+		case CE_Warning:
+			level = slog.LevelWarn
+		}
+		logger.Log(
+			context.Background(), level, msg,
+			slog.String("class", class.Name()),
+			slog.Int("code", int(num)),
+		)
+	}
+}
+
+//export goCPLErrorHandlerProxyA
+func goCPLErrorHandlerProxyA(class C.int, num C.int, message *C.char) {
+	handler := errorHandler.Load()
+	if handler == nil {
+		return
+	}
+	(*handler)(CPLErr(class), CPLErrorNum(num), C.GoString(message))
+}
@@ -9,6 +9,10 @@ The documentation is fairly limited, but the functionality fairly closely matche
 
 This wrapper has most recently been tested on Windows7, using the MinGW32_x64 compiler and GDAL version 1.11.
 
+Handle ownership
+
+Dataset, DataSource, Geometry, Feature, SpatialReference, CoordinateTransform, ColorTable, RasterAttributeTable and VSIFile values created by the wrapper own their C object and implement io.Closer.  Close may be called more than once and from any copy of the value.  Values that are never closed are destroyed by a finalizer once they become unreachable, but a finalizer may run late or not at all, so owned values should be closed; EnableHandleTracking and AssertNoLeaks report the ones that are not.  Values fetched from another object, such as Feature.Geometry or Layer.SpatialReference, are borrowed: closing them is a no-op and they are only valid while their owner is open.  Functions named *Directly, such as Feature.SetGeometryDirectly, transfer ownership of their argument.
+
 Usage
 
 A simple program to create a georeferenced blank 256x256 GeoTIFF:
@@ -35,7 +39,11 @@ A simple program to create a georeferenced blank 256x256 GeoTIFF:
 			return
 		}
 
-		dataset := driver.Create(filename, 256, 256, 1, gdal.Byte, nil)
+		dataset, err := driver.Create(filename, 256, 256, 1, gdal.Byte, nil)
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
 		defer dataset.Close()
 
 		spatialRef := gdal.CreateSpatialReference("")
@@ -0,0 +1,106 @@
+package gdal
+
+/*
+#include "go_gdal.h"
+*/
+import "C"
+import (
+	"strings"
+	"unsafe"
+)
+
+/* --------------------------------------------- */
+/* Driver introspection                          */
+/* --------------------------------------------- */
+
+// DriverCapabilities summarizes what a driver supports
+type DriverCapabilities struct {
+	ShortName string
+	LongName  string
+
+	// Kinds of dataset the driver handles
+	Raster, Vector, Multidim bool
+
+	// Ways the driver can write datasets
+	Create, CreateCopy, Update bool
+	// Support for /vsi virtual file systems
+	VirtualIO bool
+
+	// Data types accepted by Create, empty if not published
+	CreationDataTypes []DataType
+	// File name extensions, without the dot
+	Extensions []string
+	MIMEType   string
+	HelpTopic  string
+
+	// Options accepted by OpenEx and by Create/CreateCopy. Lists the
+	// driver publishes in a malformed way are left empty.
+	OpenOptions     []OptionDefinition
+	CreationOptions []OptionDefinition
+}
+
+// Fetch the capabilities this driver declares in its metadata
+func (driver Driver) Capabilities() DriverCapabilities {
+	flag := func(name string) bool {
+		return strings.EqualFold(driver.MetadataItem(name, ""), "YES")
+	}
+	caps := DriverCapabilities{
+		ShortName:  driver.ShortName(),
+		LongName:   driver.LongName(),
+		Raster:     flag(DCAP_RASTER),
+		Vector:     flag(DCAP_VECTOR),
+		Multidim:   flag(DCAP_MULTIDIM_RASTER),
+		Create:     flag(DCAP_CREATE),
+		CreateCopy: flag(DCAP_CREATECOPY),
+		Update:     flag(DCAP_UPDATE),
+		VirtualIO:  flag(DCAP_VIRTUALIO),
+		MIMEType:   driver.MetadataItem(DMD_MIMETYPE, ""),
+		HelpTopic:  driver.MetadataItem(DMD_HELPTOPIC, ""),
+	}
+	// GDAL 1.x drivers are raster only and do not declare DCAP_RASTER
+	if !caps.Raster && !caps.Vector && !caps.Multidim && VERSION_MAJOR < 2 {
+		caps.Raster = true
+	}
+
+	for _, name := range strings.Fields(driver.MetadataItem(DMD_CREATIONDATATYPES, "")) {
+		cName := C.CString(name)
+		dataType := DataType(C.GDALGetDataTypeByName(cName))
+		C.free(unsafe.Pointer(cName))
+		if dataType != Unknown {
+			caps.CreationDataTypes = append(caps.CreationDataTypes, dataType)
+		}
+	}
+
+	extensions := driver.MetadataItem(DMD_EXTENSIONS, "")
+	if extensions == "" {
+		extensions = driver.MetadataItem(DMD_EXTENSION, "")
+	}
+	caps.Extensions = strings.Fields(extensions)
+
+	caps.OpenOptions, _ = ParseOptionList(driver.MetadataItem(DMD_OPENOPTIONLIST, ""))
+	caps.CreationOptions, _ = driver.CreationOptions()
+	return caps
+}
+
+// Fetch the capabilities of every registered driver. With GDAL 1.x, where
+// OGR keeps its own driver registry, the OGR drivers are listed after the
+// GDAL ones with only the fields OGR can report filled in.
+func Drivers() []DriverCapabilities {
+	var drivers []DriverCapabilities
+	for i := 0; i < GetDriverCount(); i++ {
+		drivers = append(drivers, GetDriver(i).Capabilities())
+	}
+	if VERSION_MAJOR >= 2 {
+		return drivers
+	}
+	for i := 0; i < OGRDriverCount(); i++ {
+		driver := OGRDriverByIndex(i)
+		drivers = append(drivers, DriverCapabilities{
+			ShortName: driver.Name(),
+			LongName:  driver.Name(),
+			Vector:    true,
+			Create:    driver.TestCapability("CreateDataSource"),
+		})
+	}
+	return drivers
+}
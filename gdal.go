@@ -4,17 +4,25 @@ package gdal
 #include "go_gdal.h"
 #include "gdal_version.h"
 
+// The build flags for the whole package; the other files only include
+// go_gdal.h
 #cgo darwin pkg-config: gdal
-#cgo linux LDFLAGS: -L/usr/local/lib -lgdal
-#cgo linux CFLAGS: -I/usr/local/include
+#cgo linux,!gdal_nopkgconfig pkg-config: gdal
+#cgo linux,gdal_nopkgconfig LDFLAGS: -L/usr/local/lib -lgdal
+#cgo linux,gdal_nopkgconfig CFLAGS: -I/usr/local/include
 #cgo windows LDFLAGS: -LC:/Python27/Lib/site-packages/osgeo/lib -lgdal_i
 #cgo windows CFLAGS: -IC:/Python27/Lib/site-packages/osgeo/include/gdal
 */
 import "C"
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"reflect"
+	"runtime"
+	"runtime/cgo"
+	"sync/atomic"
 	"unsafe"
 )
 
@@ -42,39 +50,47 @@ var (
 	ErrFailure = errors.New("Failure Error")
 	ErrFatal   = errors.New("Fatal Error")
 	ErrIllegal = errors.New("Illegal Error")
+
+	// Matched by errors for functions the GDAL in use does not provide,
+	// including CPLErrors numbered CPLE_NotSupported
+	ErrNotSupported = errors.New("Not Supported Error")
 )
 
-// Error handling.  The following is bare-bones, and needs to be replaced with something more useful.
+// Error handling.  Failed calls return a *CPLError holding the message GDAL
+// recorded, which still matches the sentinel errors above through errors.Is.
 func (err _Ctype_CPLErr) Err() error {
-	switch err {
-	case 0:
+	if err == 0 {
 		return nil
-	case 1:
-		return ErrDebug
-	case 2:
-		return ErrWarning
-	case 3:
-		return ErrFailure
-	case 4:
-		return ErrFailure
 	}
-	return ErrIllegal
+	return lastError(CPLErr(err))
+}
+
+// Descriptions of the OGRERR_* codes, used when OGR fails without a message
+var ogrErrMessages = map[int]string{
+	1: "not enough data",
+	2: "not enough memory",
+	3: "unsupported geometry type",
+	4: "unsupported operation",
+	5: "corrupt data",
+	6: "failure",
+	7: "unsupported SRS",
+	8: "invalid handle",
+	9: "non existing feature",
 }
 
 func (err _Ctype_OGRErr) Err() error {
-	switch err {
-	case 0:
+	if err == 0 {
 		return nil
-	case 1:
-		return ErrDebug
-	case 2:
-		return ErrWarning
-	case 3:
-		return ErrFailure
-	case 4:
-		return ErrFailure
 	}
-	return ErrIllegal
+	cplErr := lastError(CE_Failure)
+	if cplErr.Msg == "" {
+		if msg, ok := ogrErrMessages[int(err)]; ok {
+			cplErr.Msg = "OGR error: " + msg
+		} else {
+			cplErr.Msg = fmt.Sprintf("OGR error %d", int(err))
+		}
+	}
+	return cplErr
 }
 
 // Pixel data types
@@ -114,6 +130,38 @@ func (dataType DataType) Union(dataTypeB DataType) DataType {
 	)
 }
 
+// Convert strs to a NULL terminated array of C strings, returning a
+// function that frees it. A nil slice gives a NULL array, an empty one an
+// array holding only the terminator.
+func cStringList(strs []string) (**C.char, func()) {
+	if strs == nil {
+		return nil, func() {}
+	}
+	list := (**C.char)(C.calloc(C.size_t(len(strs)+1), C.size_t(unsafe.Sizeof((*C.char)(nil)))))
+	items := unsafe.Slice(list, len(strs)+1)
+	for i, s := range strs {
+		items[i] = C.CString(s)
+	}
+	return list, func() {
+		for _, item := range items {
+			C.free(unsafe.Pointer(item))
+		}
+		C.free(unsafe.Pointer(list))
+	}
+}
+
+// Copy a NULL terminated array of C strings, such as a CSL string list
+func goStringList(list **C.char) []string {
+	if list == nil {
+		return nil
+	}
+	var strs []string
+	for _, item := range unsafe.Slice(list, C.CSLCount(list)) {
+		strs = append(strs, C.GoString(item))
+	}
+	return strs
+}
+
 //Safe array conversion
 func IntSliceToCInt(data []int) []C.int {
 	sliceSz := len(data)
@@ -164,6 +212,62 @@ const (
 	Update = Access(C.GA_Update)
 )
 
+// Flags for OpenEx. The values mirror the GDAL_OF_* constants, which are
+// not defined by GDAL 1.x headers.
+type OpenFlag uint
+
+const (
+	OF_ReadOnly     = OpenFlag(0x00)
+	OF_Update       = OpenFlag(0x01)
+	OF_Raster       = OpenFlag(0x02)
+	OF_Vector       = OpenFlag(0x04)
+	OF_GNM          = OpenFlag(0x08)
+	OF_Shared       = OpenFlag(0x20)
+	OF_VerboseError = OpenFlag(0x40)
+)
+
+// Options for OpenEx
+type OpenOptions struct {
+	// Open for update instead of read only
+	Update bool
+	// Kinds of dataset to accept; if neither is set, both are accepted
+	Raster, Vector bool
+	// Reuse an already open dataset of the same name, as OpenShared does
+	Shared bool
+	// Report an error through CPLError when the open fails
+	VerboseError bool
+	// Short names of the drivers to try, or nil for all drivers
+	AllowedDrivers []string
+	// Driver specific open options as "KEY=VALUE"
+	Options []string
+	// Files next to the dataset, or nil to let GDAL list the directory
+	SiblingFiles []string
+}
+
+// Flags returns the GDAL open flags selected by the options
+func (opts OpenOptions) Flags() OpenFlag {
+	flags := OF_ReadOnly
+	if opts.Update {
+		flags |= OF_Update
+	}
+	if opts.Raster {
+		flags |= OF_Raster
+	}
+	if opts.Vector {
+		flags |= OF_Vector
+	}
+	if !opts.Raster && !opts.Vector {
+		flags |= OF_Raster | OF_Vector
+	}
+	if opts.Shared {
+		flags |= OF_Shared
+	}
+	if opts.VerboseError {
+		flags |= OF_VerboseError
+	}
+	return flags
+}
+
 // Read/Write flag for RasterIO() method
 type RWFlag int
 
@@ -243,10 +347,13 @@ type MajorObject struct {
 
 type Dataset struct {
 	cval C.GDALDatasetH
+	*handle
 }
 
 type RasterBand struct {
 	cval C.GDALRasterBandH
+	// Handle of the dataset the band belongs to
+	dataset *handle
 }
 
 type Driver struct {
@@ -255,10 +362,12 @@ type Driver struct {
 
 type ColorTable struct {
 	cval C.GDALColorTableH
+	*handle
 }
 
 type RasterAttributeTable struct {
 	cval C.GDALRasterAttributeTableH
+	*handle
 }
 
 type AsyncReader struct {
@@ -269,6 +378,68 @@ type ColorEntry struct {
 	cval *C.GDALColorEntry
 }
 
+// Return true if this object wraps a NULL handle
+func (object MajorObject) IsNil() bool {
+	return object.cval == nil
+}
+
+// Return true if this dataset wraps a NULL handle or has been closed
+func (dataset Dataset) IsNil() bool {
+	return dataset.cval == nil || dataset.isClosed()
+}
+
+// Return true if this raster band wraps a NULL handle
+func (rasterBand RasterBand) IsNil() bool {
+	return rasterBand.cval == nil
+}
+
+// Return true if this driver wraps a NULL handle
+func (driver Driver) IsNil() bool {
+	return driver.cval == nil
+}
+
+// Return true if this color table wraps a NULL handle or has been destroyed
+func (ct ColorTable) IsNil() bool {
+	return ct.cval == nil || ct.isClosed()
+}
+
+// Return true if this raster attribute table wraps a NULL handle or has
+// been destroyed
+func (rat RasterAttributeTable) IsNil() bool {
+	return rat.cval == nil || rat.isClosed()
+}
+
+func newDataset(h C.GDALDatasetH) Dataset {
+	return Dataset{h, ownHandle("Dataset", h, func(h C.GDALDatasetH) error {
+		C.GDALClose(h)
+		return nil
+	})}
+}
+
+func newColorTable(h C.GDALColorTableH) ColorTable {
+	return ColorTable{h, ownHandle("ColorTable", h, func(h C.GDALColorTableH) error {
+		C.GDALDestroyColorTable(h)
+		return nil
+	})}
+}
+
+func newRasterAttributeTable(h C.GDALRasterAttributeTableH) RasterAttributeTable {
+	return RasterAttributeTable{h, ownHandle("RasterAttributeTable", h, func(h C.GDALRasterAttributeTableH) error {
+		C.GDALDestroyRasterAttributeTable(h)
+		return nil
+	})}
+}
+
+// Return true if this asynchronous reader wraps a NULL handle
+func (reader AsyncReader) IsNil() bool {
+	return reader.cval == nil
+}
+
+// Return true if this color entry wraps a NULL pointer
+func (entry ColorEntry) IsNil() bool {
+	return entry.cval == nil
+}
+
 /* -------------------------------------------------------------------- */
 /*      Callback "progress" function.                                   */
 /* -------------------------------------------------------------------- */
@@ -291,21 +462,107 @@ func TermProgress(complete float64, message string, data interface{}) int {
 	return int(retval)
 }
 
+// State shared between CreateScaledProgress and ScaledProgress
+type scaledProgress struct {
+	min, max float64
+	progress ProgressFunc
+	data     interface{}
+}
+
+// Report progress of a sub-operation.  The data argument must be the value
+// returned by CreateScaledProgress, which maps complete into its [min, max]
+// range of the overall operation.
 func ScaledProgress(complete float64, message string, data interface{}) int {
-	msg := C.CString(message)
-	defer C.free(unsafe.Pointer(msg))
+	scaled, ok := data.(*scaledProgress)
+	if !ok || scaled.progress == nil {
+		return 1
+	}
+	return scaled.progress(
+		scaled.min+complete*(scaled.max-scaled.min), message, scaled.data,
+	)
+}
 
-	retval := C.GDALScaledProgress(C.double(complete), msg, unsafe.Pointer(nil))
-	return int(retval)
+// Create the data to pass along with ScaledProgress so that a sub-operation
+// reports to progress within [min, max] of the overall operation.  The state
+// lives in Go memory, so unlike GDALCreateScaledProgress there is nothing to
+// destroy afterwards.
+func CreateScaledProgress(min, max float64, progress ProgressFunc, data interface{}) interface{} {
+	return &scaledProgress{min, max, progress, data}
 }
 
-func CreateScaledProgress(min, max float64, progress ProgressFunc, data unsafe.Pointer) unsafe.Pointer {
-	panic("not implemented!")
-	return nil
+// Return a progress function mapping the [0, 1] progress of a sub-operation
+// into the [min, max] range of progress (which may be nil).
+func ScaleProgress(min, max float64, progress ProgressFunc) ProgressFunc {
+	return func(complete float64, message string, data interface{}) int {
+		if progress == nil {
+			return 1
+		}
+		return progress(min+complete*(max-min), message, data)
+	}
+}
+
+// Split progress into consecutive steps of a pipeline, one per weight.  Each
+// step reports its [0, 1] progress as its share of the overall range, so that
+// progress keeps increasing from the first step to the last.
+func SplitProgress(progress ProgressFunc, weights ...float64) []ProgressFunc {
+	total := 0.0
+	for _, weight := range weights {
+		total += weight
+	}
+	steps := make([]ProgressFunc, len(weights))
+	start := 0.0
+	for i, weight := range weights {
+		share := 1.0 / float64(len(weights))
+		if total > 0 {
+			share = weight / total
+		}
+		end := start + share
+		if i == len(weights)-1 {
+			end = 1
+		}
+		steps[i] = ScaleProgress(start, end, progress)
+		start = end
+	}
+	return steps
+}
+
+// Return a progress function which aborts the running operation once ctx is
+// done, reporting to progress (which may be nil) until then.
+func ProgressFromContext(ctx context.Context, progress ProgressFunc) ProgressFunc {
+	return newContextProgress(ctx, progress).fn
+}
+
+// contextProgress is the progress function of a context-aware operation,
+// remembering whether it aborted the operation because ctx was done
+type contextProgress struct {
+	ctx      context.Context
+	progress ProgressFunc
+	aborted  atomic.Bool
 }
 
-func DestroyScaledProgress(data unsafe.Pointer) {
-	C.GDALDestroyScaledProgress(data)
+func newContextProgress(ctx context.Context, progress ProgressFunc) *contextProgress {
+	return &contextProgress{ctx: ctx, progress: progress}
+}
+
+func (cp *contextProgress) fn(complete float64, message string, data interface{}) int {
+	if cp.ctx.Err() != nil {
+		cp.aborted.Store(true)
+		return 0
+	}
+	if cp.progress == nil {
+		return 1
+	}
+	return cp.progress(complete, message, data)
+}
+
+// Make the error of an operation aborted by cp match ctx.Err() as well as
+// the "User terminated" error reported by GDAL. Other errors are returned
+// unchanged, even once ctx is done.
+func (cp *contextProgress) err(err error) error {
+	if err == nil || !cp.aborted.Load() {
+		return err
+	}
+	return fmt.Errorf("%w: %w", cp.ctx.Err(), err)
 }
 
 // -----------------------------------------------------------------------
@@ -315,9 +572,43 @@ type goGDALProgressFuncProxyArgs struct {
 	data          interface{}
 }
 
+// Progress function and data handed to GDAL as a runtime/cgo.Handle, so that
+// no Go pointer is passed to C.  The zero value stands for no progress.
+type progressArg struct {
+	handle cgo.Handle
+}
+
+// Wrap progress for a GDAL call; release must be deferred right after
+func newProgressArg(progress ProgressFunc, data interface{}) progressArg {
+	if progress == nil {
+		return progressArg{}
+	}
+	return progressArg{cgo.NewHandle(&goGDALProgressFuncProxyArgs{progress, data})}
+}
+
+// Return the C progress function, or nil when there is no progress function
+func (arg progressArg) fn() C.GDALProgressFunc {
+	if arg.handle == 0 {
+		return nil
+	}
+	return C.goGDALProgressFuncProxyB()
+}
+
+// Return the argument GDAL passes back to the C progress function
+func (arg progressArg) ptr() unsafe.Pointer {
+	return C.goGDALProgressArg(C.uintptr_t(arg.handle))
+}
+
+// Release the handle once GDAL no longer calls the progress function
+func (arg progressArg) release() {
+	if arg.handle != 0 {
+		arg.handle.Delete()
+	}
+}
+
 //export goGDALProgressFuncProxyA
-func goGDALProgressFuncProxyA(complete C.double, message *C.char, data unsafe.Pointer) int {
-	arg := (*goGDALProgressFuncProxyArgs)(data)
+func goGDALProgressFuncProxyA(complete C.double, message *C.char, handle C.uintptr_t) int {
+	arg := cgo.Handle(handle).Value().(*goGDALProgressFuncProxyArgs)
 	return arg.progresssFunc(
 		float64(complete), C.GoString(message), arg.data,
 	)
@@ -340,13 +631,26 @@ const (
 	DCAP_VIRTUALIO  = string(C.GDAL_DCAP_VIRTUALIO)
 )
 
+// Metadata items added after GDAL 1.x, spelled out since older headers
+// do not define them
+const (
+	DMD_EXTENSIONS     = "DMD_EXTENSIONS"
+	DMD_OPENOPTIONLIST = "DMD_OPENOPTIONLIST"
+
+	DCAP_RASTER          = "DCAP_RASTER"
+	DCAP_VECTOR          = "DCAP_VECTOR"
+	DCAP_MULTIDIM_RASTER = "DCAP_MULTIDIM_RASTER"
+	DCAP_UPDATE          = "DCAP_UPDATE"
+)
+
 // Create a new dataset with this driver.
 func (driver Driver) Create(
 	filename string,
 	xSize, ySize, bands int,
 	dataType DataType,
 	options []string,
-) Dataset {
+) (Dataset, error) {
+	defer pinErrorState()()
 	name := C.CString(filename)
 	defer C.free(unsafe.Pointer(name))
 
@@ -365,7 +669,10 @@ func (driver Driver) Create(
 		C.GDALDataType(dataType),
 		(**C.char)(unsafe.Pointer(&opts[0])),
 	)
-	return Dataset{h}
+	if h == nil {
+		return newDataset(h), nullHandleError("Error: dataset '%s' create error", filename)
+	}
+	return newDataset(h), nil
 }
 
 // Create a copy of a dataset
@@ -376,7 +683,9 @@ func (driver Driver) CreateCopy(
 	options []string,
 	progress ProgressFunc,
 	data interface{},
-) Dataset {
+) (Dataset, error) {
+	defer runtime.KeepAlive(sourceDataset.handle)
+	defer pinErrorState()()
 	name := C.CString(filename)
 	defer C.free(unsafe.Pointer(name))
 
@@ -388,35 +697,43 @@ func (driver Driver) CreateCopy(
 	}
 	opts[length] = (*C.char)(unsafe.Pointer(nil))
 
-	var h C.GDALDatasetH
+	arg := newProgressArg(progress, data)
+	defer arg.release()
 
-	if progress == nil {
-		h = C.GDALCreateCopy(
-			driver.cval, name,
-			sourceDataset.cval,
-			C.int(strict),
-			(**C.char)(unsafe.Pointer(&opts[0])),
-			nil,
-			nil,
-		)
-	} else {
-		arg := &goGDALProgressFuncProxyArgs{
-			progress, data,
-		}
-		h = C.GDALCreateCopy(
-			driver.cval, name,
-			sourceDataset.cval,
-			C.int(strict), (**C.char)(unsafe.Pointer(&opts[0])),
-			C.goGDALProgressFuncProxyB(),
-			unsafe.Pointer(arg),
-		)
+	h := C.GDALCreateCopy(
+		driver.cval, name,
+		sourceDataset.cval,
+		C.int(strict), (**C.char)(unsafe.Pointer(&opts[0])),
+		arg.fn(),
+		arg.ptr(),
+	)
+	if h == nil {
+		return newDataset(h), nullHandleError("Error: dataset '%s' copy error", filename)
 	}
+	return newDataset(h), nil
+}
 
-	return Dataset{h}
+// Create a copy of a dataset, aborting when ctx is done
+func (driver Driver) CreateCopyContext(
+	ctx context.Context,
+	filename string,
+	sourceDataset Dataset,
+	strict int,
+	options []string,
+	progress ProgressFunc,
+	data interface{},
+) (Dataset, error) {
+	cp := newContextProgress(ctx, progress)
+	ds, err := driver.CreateCopy(
+		filename, sourceDataset, strict, options,
+		cp.fn, data,
+	)
+	return ds, cp.err(err)
 }
 
 // Return the driver needed to access the provided dataset name.
-func IdentifyDriver(filename string, filenameList []string) Driver {
+func IdentifyDriver(filename string, filenameList []string) (Driver, error) {
+	defer pinErrorState()()
 	cFilename := C.CString(filename)
 	defer C.free(unsafe.Pointer(cFilename))
 
@@ -429,31 +746,95 @@ func IdentifyDriver(filename string, filenameList []string) Driver {
 	cFilenameList[length] = (*C.char)(unsafe.Pointer(nil))
 
 	driver := C.GDALIdentifyDriver(cFilename, (**C.char)(unsafe.Pointer(&cFilenameList[0])))
-	return Driver{driver}
+	if driver == nil {
+		return Driver{driver}, nullHandleError("Error: no driver identified for '%s'", filename)
+	}
+	return Driver{driver}, nil
 }
 
 // Open an existing dataset
 func Open(filename string, access Access) (Dataset, error) {
+	defer pinErrorState()()
 	cFilename := C.CString(filename)
 	defer C.free(unsafe.Pointer(cFilename))
 
 	dataset := C.GDALOpen(cFilename, C.GDALAccess(access))
 	if dataset == nil {
-		return Dataset{nil}, fmt.Errorf("Error: dataset '%s' open error", filename)
+		return Dataset{}, nullHandleError("Error: dataset '%s' open error", filename)
 	}
-	return Dataset{dataset}, nil
+	return newDataset(dataset), nil
 }
 
 // Open a shared existing dataset
-func OpenShared(filename string, access Access) Dataset {
+func OpenShared(filename string, access Access) (Dataset, error) {
+	defer pinErrorState()()
 	cFilename := C.CString(filename)
 	defer C.free(unsafe.Pointer(cFilename))
 
 	dataset := C.GDALOpenShared(cFilename, C.GDALAccess(access))
-	return Dataset{dataset}
+	if dataset == nil {
+		return Dataset{}, nullHandleError("Error: dataset '%s' open error", filename)
+	}
+	return newDataset(dataset), nil
+}
+
+// Open a raster and/or vector dataset with GDALOpenEx. Requires GDAL 2.0
+// or later; older versions fail with ErrFailure and CPLE_NotSupported.
+func OpenEx(filename string, opts OpenOptions) (Dataset, error) {
+	defer pinErrorState()()
+	cFilename := C.CString(filename)
+	defer C.free(unsafe.Pointer(cFilename))
+
+	cDrivers, freeDrivers := cStringList(opts.AllowedDrivers)
+	defer freeDrivers()
+	cOptions, freeOptions := cStringList(opts.Options)
+	defer freeOptions()
+	cSiblings, freeSiblings := cStringList(opts.SiblingFiles)
+	defer freeSiblings()
+
+	dataset := C.goGDALOpenEx(cFilename, C.uint(opts.Flags()), cDrivers, cOptions, cSiblings)
+	if dataset == nil {
+		return Dataset{}, nullHandleError("Error: dataset '%s' open error", filename)
+	}
+	return newDataset(dataset), nil
 }
 
-// Unimplemented: DumpOpenDatasets
+// Write a list of all open datasets to w, one line per dataset giving its
+// reference count, access mode, driver, size and description
+func DumpOpenDatasets(w io.Writer) error {
+	datasets := OpenDatasets()
+	if len(datasets) == 0 {
+		return nil
+	}
+	if _, err := fmt.Fprintf(w, "Open GDAL Datasets:\n"); err != nil {
+		return err
+	}
+	for _, dataset := range datasets {
+		dataset.GDALReferenceDataset()
+		refCount := dataset.GDALDereferenceDataset()
+
+		access := "RO"
+		if dataset.Access() == Update {
+			access = "RW"
+		}
+		driver := "(null)"
+		if drv := dataset.Driver(); !drv.IsNil() {
+			driver = drv.ShortName()
+		}
+		description := C.GoString(C.GDALGetDescription(C.GDALMajorObjectH(dataset.cval)))
+
+		_, err := fmt.Fprintf(
+			w, "  %d %s %-6s %dx%dx%d %s\n",
+			refCount, access, driver,
+			dataset.RasterXSize(), dataset.RasterYSize(), dataset.RasterCount(),
+			description,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
 // Return the driver by short name
 func GetDriverByName(driverName string) (Driver, error) {
@@ -502,6 +883,7 @@ func DestroyDriverManager() {
 
 // Delete named dataset
 func (driver Driver) DeleteDataset(name string) error {
+	defer pinErrorState()()
 	cDriver := driver.cval
 	cName := C.CString(name)
 	defer C.free(unsafe.Pointer(cName))
@@ -510,6 +892,7 @@ func (driver Driver) DeleteDataset(name string) error {
 
 // Rename named dataset
 func (driver Driver) RenameDataset(newName, oldName string) error {
+	defer pinErrorState()()
 	cDriver := driver.cval
 	cNewName := C.CString(newName)
 	defer C.free(unsafe.Pointer(cNewName))
@@ -520,6 +903,7 @@ func (driver Driver) RenameDataset(newName, oldName string) error {
 
 // Copy all files associated with the named dataset
 func (driver Driver) CopyDatasetFiles(newName, oldName string) error {
+	defer pinErrorState()()
 	cDriver := driver.cval
 	cNewName := C.CString(newName)
 	defer C.free(unsafe.Pointer(cNewName))
@@ -596,6 +980,7 @@ func (object MajorObject) SetMetadataItem(name, value, domain string) {
 // TODO: Make korrekt class hirerarchy via interfaces
 
 func (object *RasterBand) SetMetadataItem(name, value, domain string) error {
+	defer pinErrorState()()
 	c_name := C.CString(name)
 	defer C.free(unsafe.Pointer(c_name))
 
@@ -614,6 +999,7 @@ func (object *RasterBand) SetMetadataItem(name, value, domain string) error {
 // TODO: Make korrekt class hirerarchy via interfaces
 
 func (object *Dataset) SetMetadataItem(name, value, domain string) error {
+	defer pinErrorState()()
 	c_name := C.CString(name)
 	defer C.free(unsafe.Pointer(c_name))
 
@@ -651,12 +1037,14 @@ func (object *Driver) MetadataItem(name, domain string) string {
 
 // Get the driver to which this dataset relates
 func (dataset Dataset) Driver() Driver {
+	defer runtime.KeepAlive(dataset.handle)
 	driver := Driver{C.GDALGetDatasetDriver(dataset.cval)}
 	return driver
 }
 
 // Fetch files forming the dataset.
 func (dataset Dataset) FileList() []string {
+	defer runtime.KeepAlive(dataset.handle)
 	p := C.GDALGetFileList(dataset.cval)
 	var strings []string
 	q := uintptr(unsafe.Pointer(p))
@@ -672,38 +1060,44 @@ func (dataset Dataset) FileList() []string {
 	return strings
 }
 
-// Close the dataset
-func (dataset Dataset) Close() {
-	C.GDALClose(dataset.cval)
-	return
+// Close the dataset. Closing a dataset more than once, or one borrowed
+// from a raster band, is a no-op.
+func (dataset Dataset) Close() error {
+	return dataset.close()
 }
 
 // Fetch X size of raster
 func (dataset Dataset) RasterXSize() int {
+	defer runtime.KeepAlive(dataset.handle)
 	xSize := int(C.GDALGetRasterXSize(dataset.cval))
 	return xSize
 }
 
 // Fetch Y size of raster
 func (dataset Dataset) RasterYSize() int {
+	defer runtime.KeepAlive(dataset.handle)
 	ySize := int(C.GDALGetRasterYSize(dataset.cval))
 	return ySize
 }
 
 // Fetch the number of raster bands in the dataset
 func (dataset Dataset) RasterCount() int {
+	defer runtime.KeepAlive(dataset.handle)
 	count := int(C.GDALGetRasterCount(dataset.cval))
 	return count
 }
 
 // Fetch a raster band object from a dataset
 func (dataset Dataset) RasterBand(band int) RasterBand {
-	rasterBand := RasterBand{C.GDALGetRasterBand(dataset.cval, C.int(band))}
+	defer runtime.KeepAlive(dataset.handle)
+	rasterBand := RasterBand{C.GDALGetRasterBand(dataset.cval, C.int(band)), dataset.handle}
 	return rasterBand
 }
 
 // Add a band to a dataset
 func (dataset Dataset) AddBand(dataType DataType, options []string) error {
+	defer runtime.KeepAlive(dataset.handle)
+	defer pinErrorState()()
 	length := len(options)
 	cOptions := make([]*C.char, length+1)
 	for i := 0; i < length; i++ {
@@ -730,6 +1124,8 @@ const (
 )
 
 func (dataset Dataset) AutoCreateWarpedVRT(srcWKT, dstWKT string, resampleAlg ResampleAlg) (Dataset, error) {
+	defer runtime.KeepAlive(dataset.handle)
+	defer pinErrorState()()
 	c_srcWKT := C.CString(srcWKT)
 	defer C.free(unsafe.Pointer(c_srcWKT))
 	c_dstWKT := C.CString(dstWKT)
@@ -738,9 +1134,9 @@ func (dataset Dataset) AutoCreateWarpedVRT(srcWKT, dstWKT string, resampleAlg Re
 
 	 */
 	h := C.GDALAutoCreateWarpedVRT(dataset.cval, c_srcWKT, c_dstWKT, C.GDALResampleAlg(resampleAlg), 0.0, nil)
-	d := Dataset{h}
+	d := newDataset(h)
 	if h == nil {
-		return d, fmt.Errorf("AutoCreateWarpedVRT failed")
+		return d, nullHandleError("AutoCreateWarpedVRT failed")
 	}
 	return d, nil
 
@@ -749,7 +1145,9 @@ func (dataset Dataset) AutoCreateWarpedVRT(srcWKT, dstWKT string, resampleAlg Re
 // Unimplemented: GDALBeginAsyncReader
 // Unimplemented: GDALEndAsyncReader
 
-// Read / write a region of image data from multiple bands
+// Read / write a region of image data from multiple bands.
+// The buffer data type is taken from the element type of buffer.  A nil
+// bandMap selects bands 1 to bandCount.
 func (dataset Dataset) IO(
 	rwFlag RWFlag,
 	xOff, yOff, xSize, ySize int,
@@ -759,27 +1157,45 @@ func (dataset Dataset) IO(
 	bandMap []int,
 	pixelSpace, lineSpace, bandSpace int,
 ) error {
-	var dataType DataType = dataset.RasterBand(1).RasterDataType()
-	var dataPtr unsafe.Pointer
-	switch data := buffer.(type) {
-	case []int8:
-		dataPtr = unsafe.Pointer(&data[0])
-	case []uint8:
-		dataPtr = unsafe.Pointer(&data[0])
-	case []int16:
-		dataPtr = unsafe.Pointer(&data[0])
-	case []uint16:
-		dataPtr = unsafe.Pointer(&data[0])
-	case []int32:
-		dataPtr = unsafe.Pointer(&data[0])
-	case []uint32:
-		dataPtr = unsafe.Pointer(&data[0])
-	case []float32:
-		dataPtr = unsafe.Pointer(&data[0])
-	case []float64:
-		dataPtr = unsafe.Pointer(&data[0])
-	default:
-		return fmt.Errorf("Error: buffer is not a valid data type (must be a valid numeric slice)")
+	defer runtime.KeepAlive(dataset.handle)
+	defer pinErrorState()()
+	dataPtr, dataType, length, err := bufferOf(buffer)
+	if err != nil {
+		return err
+	}
+	err = checkWindow(xOff, yOff, xSize, ySize, dataset.RasterXSize(), dataset.RasterYSize())
+	if err != nil {
+		return err
+	}
+	if bandCount < 1 {
+		return fmt.Errorf("bandCount %d is less than 1", bandCount)
+	}
+	rasterCount := dataset.RasterCount()
+	if bandMap == nil {
+		// As in GDAL, a nil bandMap selects the first bandCount bands
+		if bandCount > rasterCount {
+			return fmt.Errorf("bandCount %d exceeds the %d bands of the dataset", bandCount, rasterCount)
+		}
+		bandMap = make([]int, bandCount)
+		for i := range bandMap {
+			bandMap[i] = i + 1
+		}
+	}
+	if len(bandMap) != bandCount {
+		return fmt.Errorf("bandMap has %d entries, bandCount is %d", len(bandMap), bandCount)
+	}
+	for _, band := range bandMap {
+		if band < 1 || band > rasterCount {
+			return fmt.Errorf("band %d out of range [1, %d]", band, rasterCount)
+		}
+	}
+	err = checkBufferSize(
+		length, dataType.Size()/8,
+		bufXSize, bufYSize, bandCount,
+		pixelSpace, lineSpace, bandSpace,
+	)
+	if err != nil {
+		return err
 	}
 
 	return C.GDALDatasetRasterIO(
@@ -804,6 +1220,8 @@ func (dataset Dataset) AdviseRead(
 	bandMap []int,
 	options []string,
 ) error {
+	defer runtime.KeepAlive(dataset.handle)
+	defer pinErrorState()()
 	length := len(options)
 	cOptions := make([]*C.char, length+1)
 	for i := 0; i < length; i++ {
@@ -825,12 +1243,15 @@ func (dataset Dataset) AdviseRead(
 
 // Fetch the projection definition string for this dataset
 func (dataset Dataset) Projection() string {
+	defer runtime.KeepAlive(dataset.handle)
 	proj := C.GoString(C.GDALGetProjectionRef(dataset.cval))
 	return proj
 }
 
 // Set the projection reference string
 func (dataset Dataset) SetProjection(proj string) error {
+	defer runtime.KeepAlive(dataset.handle)
+	defer pinErrorState()()
 	cProj := C.CString(proj)
 	defer C.free(unsafe.Pointer(cProj))
 
@@ -839,6 +1260,8 @@ func (dataset Dataset) SetProjection(proj string) error {
 
 // Get the affine transformation coefficients
 func (dataset Dataset) GeoTransform() ([6]float64, error) {
+	defer runtime.KeepAlive(dataset.handle)
+	defer pinErrorState()()
 	var transform [6]float64
 	err := C.GDALGetGeoTransform(dataset.cval, (*C.double)(unsafe.Pointer(&transform[0]))).Err()
 	return transform, err
@@ -846,6 +1269,8 @@ func (dataset Dataset) GeoTransform() ([6]float64, error) {
 
 // Set the affine transformation coefficients
 func (dataset Dataset) SetGeoTransform(transform [6]float64) error {
+	defer runtime.KeepAlive(dataset.handle)
+	defer pinErrorState()()
 	return C.GDALSetGeoTransform(
 		dataset.cval,
 		(*C.double)(unsafe.Pointer(&transform[0])),
@@ -872,6 +1297,7 @@ func InvGeoTransform(transform [6]float64) [6]float64 {
 
 // Get number of GCPs
 func (dataset Dataset) GDALGetGCPCount() int {
+	defer runtime.KeepAlive(dataset.handle)
 	count := C.GDALGetGCPCount(dataset.cval)
 	return int(count)
 }
@@ -882,6 +1308,7 @@ func (dataset Dataset) GDALGetGCPCount() int {
 
 // Fetch a format specific internally meaningful handle
 func (dataset Dataset) GDALGetInternalHandle(request string) unsafe.Pointer {
+	defer runtime.KeepAlive(dataset.handle)
 	cRequest := C.CString(request)
 	defer C.free(unsafe.Pointer(cRequest))
 
@@ -891,12 +1318,14 @@ func (dataset Dataset) GDALGetInternalHandle(request string) unsafe.Pointer {
 
 // Add one to dataset reference count
 func (dataset Dataset) GDALReferenceDataset() int {
+	defer runtime.KeepAlive(dataset.handle)
 	count := C.GDALReferenceDataset(dataset.cval)
 	return int(count)
 }
 
 // Subtract one from dataset reference count
 func (dataset Dataset) GDALDereferenceDataset() int {
+	defer runtime.KeepAlive(dataset.handle)
 	count := C.GDALDereferenceDataset(dataset.cval)
 	return int(count)
 }
@@ -911,10 +1340,13 @@ func (dataset Dataset) BuildOverviews(
 	progress ProgressFunc,
 	data interface{},
 ) error {
+	defer runtime.KeepAlive(dataset.handle)
+	defer pinErrorState()()
 	cResampling := C.CString(resampling)
 	defer C.free(unsafe.Pointer(cResampling))
 
-	arg := &goGDALProgressFuncProxyArgs{progress, data}
+	arg := newProgressArg(progress, data)
+	defer arg.release()
 
 	return C.GDALBuildOverviews(
 		dataset.cval,
@@ -923,27 +1355,64 @@ func (dataset Dataset) BuildOverviews(
 		(*C.int)(unsafe.Pointer(&IntSliceToCInt(overviewList)[0])),
 		C.int(nBands),
 		(*C.int)(unsafe.Pointer(&IntSliceToCInt(bandList)[0])),
-		C.goGDALProgressFuncProxyB(),
-		unsafe.Pointer(arg),
+		arg.fn(),
+		arg.ptr(),
 	).Err()
 }
 
-// Unimplemented: GDALGetOpenDatasets
+// Build raster overview(s), aborting when ctx is done
+func (dataset Dataset) BuildOverviewsContext(
+	ctx context.Context,
+	resampling string,
+	nOverviews int,
+	overviewList []int,
+	nBands int,
+	bandList []int,
+	progress ProgressFunc,
+	data interface{},
+) error {
+	cp := newContextProgress(ctx, progress)
+	err := dataset.BuildOverviews(
+		resampling, nOverviews, overviewList, nBands, bandList,
+		cp.fn, data,
+	)
+	return cp.err(err)
+}
+
+// Fetch all open datasets. The returned datasets are borrowed from their
+// owners and closing them is a no-op.
+func OpenDatasets() []Dataset {
+	var list *C.GDALDatasetH
+	var count C.int
+	C.GDALGetOpenDatasets(&list, &count)
+	if list == nil || count == 0 {
+		return nil
+	}
+	datasets := make([]Dataset, 0, int(count))
+	for _, h := range unsafe.Slice(list, int(count)) {
+		datasets = append(datasets, Dataset{h, nil})
+	}
+	return datasets
+}
 
 // Return access flag
 func (dataset Dataset) Access() Access {
+	defer runtime.KeepAlive(dataset.handle)
 	accessVal := C.GDALGetAccess(dataset.cval)
 	return Access(accessVal)
 }
 
 // Write all write cached data to disk
 func (dataset Dataset) FlushCache() {
+	defer runtime.KeepAlive(dataset.handle)
 	C.GDALFlushCache(dataset.cval)
 	return
 }
 
 // Adds a mask band to the dataset
 func (dataset Dataset) CreateMaskBand(flags int) error {
+	defer runtime.KeepAlive(dataset.handle)
+	defer pinErrorState()()
 	return C.GDALCreateDatasetMaskBand(dataset.cval, C.int(flags)).Err()
 }
 
@@ -954,7 +1423,11 @@ func (sourceDataset Dataset) CopyWholeRaster(
 	progress ProgressFunc,
 	data interface{},
 ) error {
-	arg := &goGDALProgressFuncProxyArgs{progress, data}
+	defer runtime.KeepAlive(sourceDataset.handle)
+	defer runtime.KeepAlive(destDataset.handle)
+	defer pinErrorState()()
+	arg := newProgressArg(progress, data)
+	defer arg.release()
 
 	length := len(options)
 	cOptions := make([]*C.char, length+1)
@@ -968,26 +1441,43 @@ func (sourceDataset Dataset) CopyWholeRaster(
 		sourceDataset.cval,
 		destDataset.cval,
 		(**C.char)(unsafe.Pointer(&cOptions[0])),
-		C.goGDALProgressFuncProxyB(),
-		unsafe.Pointer(arg),
+		arg.fn(),
+		arg.ptr(),
 	).Err()
 }
 
+// Copy all dataset raster data, aborting when ctx is done
+func (sourceDataset Dataset) CopyWholeRasterContext(
+	ctx context.Context,
+	destDataset Dataset,
+	options []string,
+	progress ProgressFunc,
+	data interface{},
+) error {
+	cp := newContextProgress(ctx, progress)
+	err := sourceDataset.CopyWholeRaster(
+		destDataset, options, cp.fn, data,
+	)
+	return cp.err(err)
+}
+
 /* ==================================================================== */
 /*      GDALRasterBand ... one band/channel in a dataset.               */
 /* ==================================================================== */
 
 // Fetch the pixel data type for this band
 func (rasterBand RasterBand) RasterDataType() DataType {
+	defer runtime.KeepAlive(rasterBand.dataset)
 	dataType := C.GDALGetRasterDataType(rasterBand.cval)
 	return DataType(dataType)
 }
 
 // Fetch the "natural" block size of this band
 func (rasterBand RasterBand) BlockSize() (int, int) {
-	var xSize, ySize int
-	C.GDALGetBlockSize(rasterBand.cval, (*C.int)(unsafe.Pointer(&xSize)), (*C.int)(unsafe.Pointer(&ySize)))
-	return xSize, ySize
+	defer runtime.KeepAlive(rasterBand.dataset)
+	var xSize, ySize C.int
+	C.GDALGetBlockSize(rasterBand.cval, &xSize, &ySize)
+	return int(xSize), int(ySize)
 }
 
 // Advise driver of upcoming read requests
@@ -996,6 +1486,8 @@ func (rasterBand RasterBand) AdviseRead(
 	dataType DataType,
 	options []string,
 ) error {
+	defer runtime.KeepAlive(rasterBand.dataset)
+	defer pinErrorState()()
 	length := len(options)
 	cOptions := make([]*C.char, length+1)
 	for i := 0; i < length; i++ {
@@ -1012,7 +1504,8 @@ func (rasterBand RasterBand) AdviseRead(
 	).Err()
 }
 
-// Read / Write a region of image data for this band
+// Read / Write a region of image data for this band.
+// The buffer data type is taken from the element type of buffer.
 func (rasterBand RasterBand) IO(
 	rwFlag RWFlag,
 	xOff, yOff, xSize, ySize int,
@@ -1020,27 +1513,23 @@ func (rasterBand RasterBand) IO(
 	bufXSize, bufYSize int,
 	pixelSpace, lineSpace int,
 ) error {
-	var dataType DataType = rasterBand.RasterDataType()
-	var dataPtr unsafe.Pointer
-	switch data := buffer.(type) {
-	case []int8:
-		dataPtr = unsafe.Pointer(&data[0])
-	case []uint8:
-		dataPtr = unsafe.Pointer(&data[0])
-	case []int16:
-		dataPtr = unsafe.Pointer(&data[0])
-	case []uint16:
-		dataPtr = unsafe.Pointer(&data[0])
-	case []int32:
-		dataPtr = unsafe.Pointer(&data[0])
-	case []uint32:
-		dataPtr = unsafe.Pointer(&data[0])
-	case []float32:
-		dataPtr = unsafe.Pointer(&data[0])
-	case []float64:
-		dataPtr = unsafe.Pointer(&data[0])
-	default:
-		return fmt.Errorf("Error: buffer is not a valid data type (must be a valid numeric slice)")
+	defer runtime.KeepAlive(rasterBand.dataset)
+	defer pinErrorState()()
+	dataPtr, dataType, length, err := bufferOf(buffer)
+	if err != nil {
+		return err
+	}
+	err = checkWindow(xOff, yOff, xSize, ySize, rasterBand.XSize(), rasterBand.YSize())
+	if err != nil {
+		return err
+	}
+	err = checkBufferSize(
+		length, dataType.Size()/8,
+		bufXSize, bufYSize, 1,
+		pixelSpace, lineSpace, 0,
+	)
+	if err != nil {
+		return err
 	}
 
 	return C.GDALRasterIO(
@@ -1054,88 +1543,124 @@ func (rasterBand RasterBand) IO(
 	).Err()
 }
 
-// Read a block of image data efficiently
+// Read a block of image data efficiently.
+// dataPtr must point to at least one block of the band's data type;
+// ReadBlockSlice is the checked alternative.
 func (rasterBand RasterBand) ReadBlock(xOff, yOff int, dataPtr unsafe.Pointer) error {
+	defer runtime.KeepAlive(rasterBand.dataset)
+	if err := rasterBand.checkBlock(xOff, yOff); err != nil {
+		return err
+	}
+	if dataPtr == nil {
+		return fmt.Errorf("nil block buffer")
+	}
+	defer pinErrorState()()
 	return C.GDALReadBlock(rasterBand.cval, C.int(xOff), C.int(yOff), dataPtr).Err()
 }
 
-// Write a block of image data efficiently
+// Write a block of image data efficiently.
+// dataPtr must point to at least one block of the band's data type;
+// WriteBlockSlice is the checked alternative.
 func (rasterBand RasterBand) WriteBlock(xOff, yOff int, dataPtr unsafe.Pointer) error {
+	defer runtime.KeepAlive(rasterBand.dataset)
+	if err := rasterBand.checkBlock(xOff, yOff); err != nil {
+		return err
+	}
+	if dataPtr == nil {
+		return fmt.Errorf("nil block buffer")
+	}
+	defer pinErrorState()()
 	return C.GDALWriteBlock(rasterBand.cval, C.int(xOff), C.int(yOff), dataPtr).Err()
 }
 
 // Fetch X size of raster
 func (rasterBand RasterBand) XSize() int {
+	defer runtime.KeepAlive(rasterBand.dataset)
 	xSize := C.GDALGetRasterBandXSize(rasterBand.cval)
 	return int(xSize)
 }
 
 // Fetch Y size of raster
 func (rasterBand RasterBand) YSize() int {
+	defer runtime.KeepAlive(rasterBand.dataset)
 	ySize := C.GDALGetRasterBandYSize(rasterBand.cval)
 	return int(ySize)
 }
 
 // Find out if we have update permission for this band
 func (rasterBand RasterBand) GetAccess() Access {
+	defer runtime.KeepAlive(rasterBand.dataset)
 	access := C.GDALGetRasterAccess(rasterBand.cval)
 	return Access(access)
 }
 
 // Fetch the band number of this raster band
 func (rasterBand RasterBand) BandNumber() int {
+	defer runtime.KeepAlive(rasterBand.dataset)
 	bandNumber := C.GDALGetBandNumber(rasterBand.cval)
 	return int(bandNumber)
 }
 
-// Fetch the owning dataset handle
+// Fetch the owning dataset handle, borrowed from the band
 func (rasterBand RasterBand) GetDataset() Dataset {
+	defer runtime.KeepAlive(rasterBand.dataset)
 	dataset := C.GDALGetBandDataset(rasterBand.cval)
-	return Dataset{dataset}
+	return Dataset{dataset, borrowHandle(rasterBand.dataset)}
 }
 
 // How should this band be interpreted as color?
 func (rasterBand RasterBand) ColorInterp() ColorInterp {
+	defer runtime.KeepAlive(rasterBand.dataset)
 	colorInterp := C.GDALGetRasterColorInterpretation(rasterBand.cval)
 	return ColorInterp(colorInterp)
 }
 
 // Set color interpretation of the raster band
 func (rasterBand RasterBand) SetColorInterp(colorInterp ColorInterp) error {
+	defer runtime.KeepAlive(rasterBand.dataset)
+	defer pinErrorState()()
 	return C.GDALSetRasterColorInterpretation(rasterBand.cval, C.GDALColorInterp(colorInterp)).Err()
 }
 
 // Fetch the color table associated with this raster band
 func (rasterBand RasterBand) ColorTable() ColorTable {
+	defer runtime.KeepAlive(rasterBand.dataset)
 	colorTable := C.GDALGetRasterColorTable(rasterBand.cval)
-	return ColorTable{colorTable}
+	return ColorTable{colorTable, borrowHandle(rasterBand.dataset)}
 }
 
 // Set the raster color table for this raster band
 func (rasterBand RasterBand) SetColorTable(colorTable ColorTable) error {
+	defer runtime.KeepAlive(rasterBand.dataset)
+	defer runtime.KeepAlive(colorTable.handle)
+	defer pinErrorState()()
 	return C.GDALSetRasterColorTable(rasterBand.cval, colorTable.cval).Err()
 }
 
 // Check for arbitrary overviews
 func (rasterBand RasterBand) HasArbitraryOverviews() int {
+	defer runtime.KeepAlive(rasterBand.dataset)
 	yes := C.GDALHasArbitraryOverviews(rasterBand.cval)
 	return int(yes)
 }
 
 // Return the number of overview layers available
 func (rasterBand RasterBand) OverviewCount() int {
+	defer runtime.KeepAlive(rasterBand.dataset)
 	count := C.GDALGetOverviewCount(rasterBand.cval)
 	return int(count)
 }
 
 // Fetch overview raster band object
 func (rasterBand RasterBand) Overview(level int) RasterBand {
+	defer runtime.KeepAlive(rasterBand.dataset)
 	overview := C.GDALGetOverview(rasterBand.cval, C.int(level))
-	return RasterBand{overview}
+	return RasterBand{overview, borrowHandle(rasterBand.dataset)}
 }
 
 // Fetch the no data value for this band
 func (rasterBand RasterBand) NoDataValue() (val float64, valid bool) {
+	defer runtime.KeepAlive(rasterBand.dataset)
 	var success int
 	noDataVal := C.GDALGetRasterNoDataValue(rasterBand.cval, (*C.int)(unsafe.Pointer(&success)))
 	return float64(noDataVal), success != 0
@@ -1143,11 +1668,14 @@ func (rasterBand RasterBand) NoDataValue() (val float64, valid bool) {
 
 // Set the no data value for this band
 func (rasterBand RasterBand) SetNoDataValue(val float64) error {
+	defer runtime.KeepAlive(rasterBand.dataset)
+	defer pinErrorState()()
 	return C.GDALSetRasterNoDataValue(rasterBand.cval, C.double(val)).Err()
 }
 
 // Fetch the list of category names for this raster
 func (rasterBand RasterBand) CategoryNames() []string {
+	defer runtime.KeepAlive(rasterBand.dataset)
 	p := C.GDALGetRasterCategoryNames(rasterBand.cval)
 	var strings []string
 	q := uintptr(unsafe.Pointer(p))
@@ -1165,6 +1693,8 @@ func (rasterBand RasterBand) CategoryNames() []string {
 
 // Set the category names for this band
 func (rasterBand RasterBand) SetRasterCategoryNames(names []string) error {
+	defer runtime.KeepAlive(rasterBand.dataset)
+	defer pinErrorState()()
 	length := len(names)
 	cStrings := make([]*C.char, length+1)
 	for i := 0; i < length; i++ {
@@ -1178,6 +1708,7 @@ func (rasterBand RasterBand) SetRasterCategoryNames(names []string) error {
 
 // Fetch the minimum value for this band
 func (rasterBand RasterBand) GetMinimum() (val float64, valid bool) {
+	defer runtime.KeepAlive(rasterBand.dataset)
 	var success int
 	min := C.GDALGetRasterMinimum(rasterBand.cval, (*C.int)(unsafe.Pointer(&success)))
 	return float64(min), success != 0
@@ -1185,13 +1716,18 @@ func (rasterBand RasterBand) GetMinimum() (val float64, valid bool) {
 
 // Fetch the maximum value for this band
 func (rasterBand RasterBand) GetMaximum() (val float64, valid bool) {
+	defer runtime.KeepAlive(rasterBand.dataset)
 	var success int
 	max := C.GDALGetRasterMaximum(rasterBand.cval, (*C.int)(unsafe.Pointer(&success)))
 	return float64(max), success != 0
 }
 
 // Fetch image statistics
+//
+// Deprecated: Use RasterStatistics, which takes bool options and returns
+// an error instead of zeros when GDAL fails.
 func (rasterBand RasterBand) GetStatistics(approxOK, force int) (min, max, mean, stdDev float64) {
+	defer runtime.KeepAlive(rasterBand.dataset)
 	C.GDALGetRasterStatistics(
 		rasterBand.cval,
 		C.int(approxOK),
@@ -1204,13 +1740,38 @@ func (rasterBand RasterBand) GetStatistics(approxOK, force int) (min, max, mean,
 	return min, max, mean, stdDev
 }
 
+// Fetch the image statistics cached by GDAL, computing them if force is
+// set. Without force, an error is returned when no statistics are cached.
+// Statistics also counts the valid and nodata pixels.
+func (rasterBand RasterBand) RasterStatistics(
+	approxOK, force bool,
+) (min, max, mean, stdDev float64, err error) {
+	defer runtime.KeepAlive(rasterBand.dataset)
+	defer pinErrorState()()
+	err = C.GDALGetRasterStatistics(
+		rasterBand.cval,
+		BoolToCInt(approxOK),
+		BoolToCInt(force),
+		(*C.double)(unsafe.Pointer(&min)),
+		(*C.double)(unsafe.Pointer(&max)),
+		(*C.double)(unsafe.Pointer(&mean)),
+		(*C.double)(unsafe.Pointer(&stdDev)),
+	).Err()
+	return min, max, mean, stdDev, err
+}
+
 // Compute image statistics
+//
+// Deprecated: Use ComputeRasterStatistics, which takes a bool option and
+// returns an error instead of zeros when GDAL fails.
 func (rasterBand RasterBand) ComputeStatistics(
 	approxOK int,
 	progress ProgressFunc,
 	data interface{},
 ) (min, max, mean, stdDev float64) {
-	arg := &goGDALProgressFuncProxyArgs{progress, data}
+	defer runtime.KeepAlive(rasterBand.dataset)
+	arg := newProgressArg(progress, data)
+	defer arg.release()
 
 	C.GDALComputeRasterStatistics(
 		rasterBand.cval,
@@ -1219,14 +1780,41 @@ func (rasterBand RasterBand) ComputeStatistics(
 		(*C.double)(unsafe.Pointer(&max)),
 		(*C.double)(unsafe.Pointer(&mean)),
 		(*C.double)(unsafe.Pointer(&stdDev)),
-		C.goGDALProgressFuncProxyB(),
-		unsafe.Pointer(arg),
+		arg.fn(),
+		arg.ptr(),
 	)
 	return min, max, mean, stdDev
 }
 
+// Compute image statistics with GDAL, reading a subset of the pixels if
+// approxOK is set
+func (rasterBand RasterBand) ComputeRasterStatistics(
+	approxOK bool,
+	progress ProgressFunc,
+	data interface{},
+) (min, max, mean, stdDev float64, err error) {
+	defer runtime.KeepAlive(rasterBand.dataset)
+	defer pinErrorState()()
+	arg := newProgressArg(progress, data)
+	defer arg.release()
+
+	err = C.GDALComputeRasterStatistics(
+		rasterBand.cval,
+		BoolToCInt(approxOK),
+		(*C.double)(unsafe.Pointer(&min)),
+		(*C.double)(unsafe.Pointer(&max)),
+		(*C.double)(unsafe.Pointer(&mean)),
+		(*C.double)(unsafe.Pointer(&stdDev)),
+		arg.fn(),
+		arg.ptr(),
+	).Err()
+	return min, max, mean, stdDev, err
+}
+
 // Set statistics on raster band
 func (rasterBand RasterBand) SetStatistics(min, max, mean, stdDev float64) error {
+	defer runtime.KeepAlive(rasterBand.dataset)
+	defer pinErrorState()()
 	return C.GDALSetRasterStatistics(
 		rasterBand.cval,
 		C.double(min),
@@ -1238,12 +1826,15 @@ func (rasterBand RasterBand) SetStatistics(min, max, mean, stdDev float64) error
 
 // Return raster unit type
 func (rasterBand RasterBand) GetUnitType() string {
+	defer runtime.KeepAlive(rasterBand.dataset)
 	cString := C.GDALGetRasterUnitType(rasterBand.cval)
 	return C.GoString(cString)
 }
 
 // Set unit type
 func (rasterBand RasterBand) SetUnitType(unit string) error {
+	defer runtime.KeepAlive(rasterBand.dataset)
+	defer pinErrorState()()
 	cString := C.CString(unit)
 	defer C.free(unsafe.Pointer(cString))
 
@@ -1252,6 +1843,7 @@ func (rasterBand RasterBand) SetUnitType(unit string) error {
 
 // Fetch the raster value offset
 func (rasterBand RasterBand) GetOffset() (float64, bool) {
+	defer runtime.KeepAlive(rasterBand.dataset)
 	var success int
 	val := C.GDALGetRasterOffset(rasterBand.cval, (*C.int)(unsafe.Pointer(&success)))
 	return float64(val), success != 0
@@ -1259,11 +1851,14 @@ func (rasterBand RasterBand) GetOffset() (float64, bool) {
 
 // Set scaling offset
 func (rasterBand RasterBand) SetOffset(offset float64) error {
+	defer runtime.KeepAlive(rasterBand.dataset)
+	defer pinErrorState()()
 	return C.GDALSetRasterOffset(rasterBand.cval, C.double(offset)).Err()
 }
 
 // Fetch the raster value scale
 func (rasterBand RasterBand) GetScale() (float64, bool) {
+	defer runtime.KeepAlive(rasterBand.dataset)
 	var success int
 	val := C.GDALGetRasterScale(rasterBand.cval, (*C.int)(unsafe.Pointer(&success)))
 	return float64(val), success != 0
@@ -1271,11 +1866,17 @@ func (rasterBand RasterBand) GetScale() (float64, bool) {
 
 // Set scaling ratio
 func (rasterBand RasterBand) SetScale(scale float64) error {
+	defer runtime.KeepAlive(rasterBand.dataset)
+	defer pinErrorState()()
 	return C.GDALSetRasterScale(rasterBand.cval, C.double(scale)).Err()
 }
 
 // Compute the min / max values for a band
+//
+// Deprecated: Use ComputeRasterMinMax, which takes a bool option and
+// returns an error instead of zeros when GDAL fails.
 func (rasterBand RasterBand) ComputeMinMax(approxOK int) (min, max float64) {
+	defer runtime.KeepAlive(rasterBand.dataset)
 	var minmax [2]float64
 	C.GDALComputeRasterMinMax(
 		rasterBand.cval,
@@ -1284,8 +1885,22 @@ func (rasterBand RasterBand) ComputeMinMax(approxOK int) (min, max float64) {
 	return minmax[0], minmax[1]
 }
 
+// Compute the min / max values for a band, reading a subset of the pixels
+// if approxOK is set
+func (rasterBand RasterBand) ComputeRasterMinMax(approxOK bool) (min, max float64, err error) {
+	defer runtime.KeepAlive(rasterBand.dataset)
+	defer pinErrorState()()
+	var minmax [2]float64
+	C.GDALComputeRasterMinMax(
+		rasterBand.cval,
+		BoolToCInt(approxOK),
+		(*C.double)(unsafe.Pointer(&minmax[0])))
+	return minmax[0], minmax[1], recordedError()
+}
+
 // Flush raster data cache
 func (rasterBand RasterBand) FlushCache() {
+	defer runtime.KeepAlive(rasterBand.dataset)
 	C.GDALFlushRasterCache(rasterBand.cval)
 }
 
@@ -1297,9 +1912,10 @@ func (rb RasterBand) Histogram(
 	progress ProgressFunc,
 	data interface{},
 ) ([]uint64, error) {
-	arg := &goGDALProgressFuncProxyArgs{
-		progress, data,
-	}
+	defer runtime.KeepAlive(rb.dataset)
+	defer pinErrorState()()
+	arg := newProgressArg(progress, data)
+	defer arg.release()
 
 	histogram := make([]C.int, buckets)
 	var err error
@@ -1311,8 +1927,8 @@ func (rb RasterBand) Histogram(
 		(*C.int)(unsafe.Pointer(&histogram[0])),
 		C.int(includeOutOfRange),
 		C.int(approxOK),
-		C.goGDALProgressFuncProxyB(),
-		unsafe.Pointer(arg),
+		arg.fn(),
+		arg.ptr(),
 	).Err(); err != nil {
 		return nil, err
 	} else {
@@ -1327,9 +1943,10 @@ func (rb RasterBand) DefaultHistogram(
 	progress ProgressFunc,
 	data interface{},
 ) (min, max float64, buckets int, histogram []uint64, err error) {
-	arg := &goGDALProgressFuncProxyArgs{
-		progress, data,
-	}
+	defer runtime.KeepAlive(rb.dataset)
+	defer pinErrorState()()
+	arg := newProgressArg(progress, data)
+	defer arg.release()
 
 	var cHistogram *C.int
 
@@ -1340,8 +1957,8 @@ func (rb RasterBand) DefaultHistogram(
 		(*C.int)(unsafe.Pointer(&buckets)),
 		&cHistogram,
 		C.int(force),
-		C.goGDALProgressFuncProxyB(),
-		unsafe.Pointer(arg),
+		arg.fn(),
+		arg.ptr(),
 	).Err()
 
 	sliceHeader := (*reflect.SliceHeader)(unsafe.Pointer(&histogram))
@@ -1355,47 +1972,101 @@ func (rb RasterBand) DefaultHistogram(
 // Set default raster histogram
 // Unimplemented: SetDefaultHistogram
 
-// Unimplemented: GetRandomRasterSample
+// Fetch a sample of at most samples valid pixel values spread over the band
+func (rasterBand RasterBand) RandomRasterSample(samples int) ([]float32, error) {
+	defer runtime.KeepAlive(rasterBand.dataset)
+	if samples <= 0 {
+		return nil, fmt.Errorf("invalid sample count %d", samples)
+	}
+	defer pinErrorState()()
+	buf := make([]float32, samples)
+	count := C.GDALGetRandomRasterSample(
+		rasterBand.cval, C.int(samples), (*C.float)(unsafe.Pointer(&buf[0])),
+	)
+	if err := recordedError(); err != nil {
+		return nil, err
+	}
+	return buf[:int(count)], nil
+}
 
 // Fetch best sampling overviews
 // Unimplemented: GetRasterSampleOverview
 
 // Fill this band with a constant value
 func (rasterBand RasterBand) Fill(real, imaginary float64) error {
+	defer runtime.KeepAlive(rasterBand.dataset)
+	defer pinErrorState()()
 	return C.GDALFillRaster(rasterBand.cval, C.double(real), C.double(imaginary)).Err()
 }
 
-// Unimplemented: ComputeBandStats
+// Compute the mean and standard deviation of the band from every
+// sampleStep-th line
+func (rasterBand RasterBand) ComputeBandStats(
+	sampleStep int,
+	progress ProgressFunc,
+	data interface{},
+) (mean, stdDev float64, err error) {
+	defer runtime.KeepAlive(rasterBand.dataset)
+	defer pinErrorState()()
+	arg := newProgressArg(progress, data)
+	defer arg.release()
+
+	err = C.GDALComputeBandStats(
+		rasterBand.cval,
+		C.int(sampleStep),
+		(*C.double)(unsafe.Pointer(&mean)),
+		(*C.double)(unsafe.Pointer(&stdDev)),
+		arg.fn(),
+		arg.ptr(),
+	).Err()
+	return mean, stdDev, err
+}
 
 // Unimplemented: OverviewMagnitudeCorrection
 
 // Fetch default Raster Attribute Table
 func (rasterBand RasterBand) GetDefaultRAT() RasterAttributeTable {
+	defer runtime.KeepAlive(rasterBand.dataset)
 	rat := C.GDALGetDefaultRAT(rasterBand.cval)
-	return RasterAttributeTable{rat}
+	return RasterAttributeTable{rat, borrowHandle(rasterBand.dataset)}
 }
 
 // Set default Raster Attribute Table
 func (rasterBand RasterBand) SetDefaultRAT(rat RasterAttributeTable) error {
+	defer runtime.KeepAlive(rasterBand.dataset)
+	defer runtime.KeepAlive(rat.handle)
+	defer pinErrorState()()
 	return C.GDALSetDefaultRAT(rasterBand.cval, rat.cval).Err()
 }
 
 // Unimplemented: AddDerivedBandPixelFunc
 
+// Status flags of a mask band
+const (
+	GMF_AllValid   = 0x01
+	GMF_PerDataset = 0x02
+	GMF_Alpha      = 0x04
+	GMF_NoData     = 0x08
+)
+
 // Return the mask band associated with the band
 func (rasterBand RasterBand) GetMaskBand() RasterBand {
+	defer runtime.KeepAlive(rasterBand.dataset)
 	mask := C.GDALGetMaskBand(rasterBand.cval)
-	return RasterBand{mask}
+	return RasterBand{mask, borrowHandle(rasterBand.dataset)}
 }
 
 // Return the status flags of the mask band associated with the band
 func (rasterBand RasterBand) GetMaskFlags() int {
+	defer runtime.KeepAlive(rasterBand.dataset)
 	flags := C.GDALGetMaskFlags(rasterBand.cval)
 	return int(flags)
 }
 
 // Adds a mask band to the current band
 func (rasterBand RasterBand) CreateMaskBand(flags int) error {
+	defer runtime.KeepAlive(rasterBand.dataset)
+	defer pinErrorState()()
 	return C.GDALCreateMaskBand(rasterBand.cval, C.int(flags)).Err()
 }
 
@@ -1406,7 +2077,11 @@ func (sourceRaster RasterBand) RasterBandCopyWholeRaster(
 	progress ProgressFunc,
 	data interface{},
 ) error {
-	arg := &goGDALProgressFuncProxyArgs{progress, data}
+	defer runtime.KeepAlive(sourceRaster.dataset)
+	defer runtime.KeepAlive(destRaster.dataset)
+	defer pinErrorState()()
+	arg := newProgressArg(progress, data)
+	defer arg.release()
 
 	length := len(options)
 	cOptions := make([]*C.char, length+1)
@@ -1420,11 +2095,26 @@ func (sourceRaster RasterBand) RasterBandCopyWholeRaster(
 		sourceRaster.cval,
 		destRaster.cval,
 		(**C.char)(unsafe.Pointer(&cOptions[0])),
-		C.goGDALProgressFuncProxyB(),
-		unsafe.Pointer(arg),
+		arg.fn(),
+		arg.ptr(),
 	).Err()
 }
 
+// Copy all raster band raster data, aborting when ctx is done
+func (sourceRaster RasterBand) RasterBandCopyWholeRasterContext(
+	ctx context.Context,
+	destRaster RasterBand,
+	options []string,
+	progress ProgressFunc,
+	data interface{},
+) error {
+	cp := newContextProgress(ctx, progress)
+	err := sourceRaster.RasterBandCopyWholeRaster(
+		destRaster, options, cp.fn, data,
+	)
+	return cp.err(err)
+}
+
 // Generate downsampled overviews
 // Unimplemented: RegenerateOverviews
 
@@ -1443,39 +2133,49 @@ func (sourceRaster RasterBand) RasterBandCopyWholeRaster(
 // Construct a new color table
 func CreateColorTable(interp PaletteInterp) ColorTable {
 	ct := C.GDALCreateColorTable(C.GDALPaletteInterp(interp))
-	return ColorTable{ct}
+	return newColorTable(ct)
 }
 
 // Destroy the color table
 func (ct ColorTable) Destroy() {
-	C.GDALDestroyColorTable(ct.cval)
+	ct.close()
+}
+
+// Destroy the color table; a no-op for tables borrowed from a raster band
+func (ct ColorTable) Close() error {
+	return ct.close()
 }
 
 // Make a copy of the color table
 func (ct ColorTable) Clone() ColorTable {
+	defer runtime.KeepAlive(ct.handle)
 	newCT := C.GDALCloneColorTable(ct.cval)
-	return ColorTable{newCT}
+	return newColorTable(newCT)
 }
 
 // Fetch palette interpretation
 func (ct ColorTable) PaletteInterpretation() PaletteInterp {
+	defer runtime.KeepAlive(ct.handle)
 	pi := C.GDALGetPaletteInterpretation(ct.cval)
 	return PaletteInterp(pi)
 }
 
 // Get number of color entries in table
 func (ct ColorTable) EntryCount() int {
+	defer runtime.KeepAlive(ct.handle)
 	count := C.GDALGetColorEntryCount(ct.cval)
 	return int(count)
 }
 
 // Fetch a color entry from table
 func (ct ColorTable) Entry(index int) ColorEntry {
+	defer runtime.KeepAlive(ct.handle)
 	entry := C.GDALGetColorEntry(ct.cval, C.int(index))
 	return ColorEntry{entry}
 }
 
 func (ct ColorTable) GetColorEntryAsRGB(index int, entry ColorEntry) int {
+	defer runtime.KeepAlive(ct.handle)
 	color := C.GDALGetColorEntryAsRGB(ct.cval, C.int(index), entry.cval)
 	return int(color)
 }
@@ -1484,11 +2184,13 @@ func (ct ColorTable) GetColorEntryAsRGB(index int, entry ColorEntry) int {
 
 // Set entry in color table
 func (ct ColorTable) SetEntry(index int, entry ColorEntry) {
+	defer runtime.KeepAlive(ct.handle)
 	C.GDALSetColorEntry(ct.cval, C.int(index), entry.cval)
 }
 
 // Create color ramp
 func (ct ColorTable) CreateColorRamp(start, end int, startColor, endColor ColorEntry) {
+	defer runtime.KeepAlive(ct.handle)
 	C.GDALCreateColorRamp(ct.cval, C.int(start), startColor.cval, C.int(end), endColor.cval)
 }
 
@@ -1531,70 +2233,85 @@ const (
 // Construct empty raster attribute table
 func CreateRasterAttributeTable() RasterAttributeTable {
 	rat := C.GDALCreateRasterAttributeTable()
-	return RasterAttributeTable{rat}
+	return newRasterAttributeTable(rat)
 }
 
 // Destroy a RAT
 func (rat RasterAttributeTable) Destroy() {
-	C.GDALDestroyRasterAttributeTable(rat.cval)
+	rat.close()
+}
+
+// Destroy a RAT; a no-op for tables borrowed from a raster band
+func (rat RasterAttributeTable) Close() error {
+	return rat.close()
 }
 
 // Fetch table column count
 func (rat RasterAttributeTable) ColumnCount() int {
+	defer runtime.KeepAlive(rat.handle)
 	count := C.GDALRATGetColumnCount(rat.cval)
 	return int(count)
 }
 
 // Fetch the name of indicated column
 func (rat RasterAttributeTable) NameOfCol(index int) string {
+	defer runtime.KeepAlive(rat.handle)
 	name := C.GDALRATGetNameOfCol(rat.cval, C.int(index))
 	return C.GoString(name)
 }
 
 // Fetch the usage of indicated column
 func (rat RasterAttributeTable) UsageOfCol(index int) RATFieldUsage {
+	defer runtime.KeepAlive(rat.handle)
 	rfu := C.GDALRATGetUsageOfCol(rat.cval, C.int(index))
 	return RATFieldUsage(rfu)
 }
 
 // Fetch the type of indicated column
 func (rat RasterAttributeTable) TypeOfCol(index int) RATFieldType {
+	defer runtime.KeepAlive(rat.handle)
 	rft := C.GDALRATGetTypeOfCol(rat.cval, C.int(index))
 	return RATFieldType(rft)
 }
 
 // Fetch column index for indicated usage
 func (rat RasterAttributeTable) ColOfUsage(rfu RATFieldUsage) int {
+	defer runtime.KeepAlive(rat.handle)
 	index := C.GDALRATGetColOfUsage(rat.cval, C.GDALRATFieldUsage(rfu))
 	return int(index)
 }
 
 // Fetch row count
 func (rat RasterAttributeTable) RowCount() int {
+	defer runtime.KeepAlive(rat.handle)
 	count := C.GDALRATGetRowCount(rat.cval)
 	return int(count)
 }
 
 // Fetch field value as string
 func (rat RasterAttributeTable) ValueAsString(row, field int) string {
+	defer runtime.KeepAlive(rat.handle)
 	cString := C.GDALRATGetValueAsString(rat.cval, C.int(row), C.int(field))
 	return C.GoString(cString)
 }
 
 // Fetch field value as integer
 func (rat RasterAttributeTable) ValueAsInt(row, field int) int {
+	defer runtime.KeepAlive(rat.handle)
 	val := C.GDALRATGetValueAsInt(rat.cval, C.int(row), C.int(field))
 	return int(val)
 }
 
 // Fetch field value as float64
 func (rat RasterAttributeTable) ValueAsFloat64(row, field int) float64 {
+	defer runtime.KeepAlive(rat.handle)
 	val := C.GDALRATGetValueAsDouble(rat.cval, C.int(row), C.int(field))
 	return float64(val)
 }
 
 // Set field value from string
 func (rat RasterAttributeTable) SetValueAsString(row, field int, val string) {
+	defer runtime.KeepAlive(rat.handle)
 	cVal := C.CString(val)
 	defer C.free(unsafe.Pointer(cVal))
 	C.GDALRATSetValueAsString(rat.cval, C.int(row), C.int(field), cVal)
@@ -1602,21 +2319,26 @@ func (rat RasterAttributeTable) SetValueAsString(row, field int, val string) {
 
 // Set field value from integer
 func (rat RasterAttributeTable) SetValueAsInt(row, field, val int) {
+	defer runtime.KeepAlive(rat.handle)
 	C.GDALRATSetValueAsInt(rat.cval, C.int(row), C.int(field), C.int(val))
 }
 
 // Set field value from float64
 func (rat RasterAttributeTable) SetValueAsFloat64(row, field int, val float64) {
+	defer runtime.KeepAlive(rat.handle)
 	C.GDALRATSetValueAsDouble(rat.cval, C.int(row), C.int(field), C.double(val))
 }
 
 // Set row count
 func (rat RasterAttributeTable) SetRowCount(count int) {
+	defer runtime.KeepAlive(rat.handle)
 	C.GDALRATSetRowCount(rat.cval, C.int(count))
 }
 
 // Create new column
 func (rat RasterAttributeTable) CreateColumn(name string, rft RATFieldType, rfu RATFieldUsage) error {
+	defer runtime.KeepAlive(rat.handle)
+	defer pinErrorState()()
 	cName := C.CString(name)
 	defer C.free(unsafe.Pointer(cName))
 	return C.GDALRATCreateColumn(rat.cval, cName, C.GDALRATFieldType(rft), C.GDALRATFieldUsage(rfu)).Err()
@@ -1624,24 +2346,31 @@ func (rat RasterAttributeTable) CreateColumn(name string, rft RATFieldType, rfu
 
 // Set linear binning information
 func (rat RasterAttributeTable) SetLinearBinning(row0min, binsize float64) error {
+	defer runtime.KeepAlive(rat.handle)
+	defer pinErrorState()()
 	return C.GDALRATSetLinearBinning(rat.cval, C.double(row0min), C.double(binsize)).Err()
 }
 
 // Fetch linear binning information
 func (rat RasterAttributeTable) LinearBinning() (row0min, binsize float64, exists bool) {
+	defer runtime.KeepAlive(rat.handle)
 	success := C.GDALRATGetLinearBinning(rat.cval, (*C.double)(&row0min), (*C.double)(&binsize))
 	return row0min, binsize, success != 0
 }
 
 // Initialize RAT from color table
 func (rat RasterAttributeTable) FromColorTable(ct ColorTable) error {
+	defer runtime.KeepAlive(rat.handle)
+	defer runtime.KeepAlive(ct.handle)
+	defer pinErrorState()()
 	return C.GDALRATInitializeFromColorTable(rat.cval, ct.cval).Err()
 }
 
 // Translate RAT to a color table
 func (rat RasterAttributeTable) ToColorTable(count int) ColorTable {
+	defer runtime.KeepAlive(rat.handle)
 	ct := C.GDALRATTranslateToColorTable(rat.cval, C.int(count))
-	return ColorTable{ct}
+	return newColorTable(ct)
 }
 
 // Dump RAT in readable form to a file
@@ -1649,6 +2378,7 @@ func (rat RasterAttributeTable) ToColorTable(count int) ColorTable {
 
 // Get row for pixel value
 func (rat RasterAttributeTable) RowOfValue(val float64) (int, bool) {
+	defer runtime.KeepAlive(rat.handle)
 	row := C.GDALRATGetRowOfValue(rat.cval, C.double(val))
 	return int(row), row != -1
 }
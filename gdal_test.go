@@ -4,7 +4,38 @@
 
 package gdal
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"io/fs"
+	"math"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// newMemDataset creates an in-memory dataset that is closed when the test
+// ends
+func newMemDataset(t *testing.T, xSize, ySize, bands int, dataType DataType) Dataset {
+	t.Helper()
+	drv, err := GetDriverByName("MEM")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	ds, err := drv.Create("", xSize, ySize, bands, dataType, nil)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	t.Cleanup(func() { ds.Close() })
+	return ds
+}
 
 func TestTiffDriver(t *testing.T) {
 	_, err := GetDriverByName("GTiff")
@@ -23,7 +54,10 @@ func TestHistogram(t *testing.T) {
 	if err != nil {
 		t.Errorf("%+v", err)
 	}
-	ds := drv.Create("/vsimem/tmp", 10, 10, 1, Byte, nil)
+	ds, err := drv.Create("/vsimem/tmp", 10, 10, 1, Byte, nil)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
 	defer ds.Close()
 	band := ds.RasterBand(1)
 	data := make([]uint8, 100)
@@ -54,3 +88,969 @@ func TestConfigOption(t *testing.T) {
 		t.Errorf("Invalid value: %s\n", value)
 	}
 }
+
+func TestCPLError(t *testing.T) {
+	drv, err := GetDriverByName("GTiff")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	err = drv.DeleteDataset("/nonexistent/gdal_go_test.tif")
+	if !errors.Is(err, ErrFailure) {
+		t.Fatalf("expected ErrFailure, got: %+v", err)
+	}
+	var cplErr *CPLError
+	if !errors.As(err, &cplErr) || cplErr.Msg == "" {
+		t.Errorf("expected a CPL error message, got: %+v", err)
+	}
+}
+
+func TestErrorHandler(t *testing.T) {
+	var classes []CPLErr
+	var messages []string
+	SetErrorHandler(func(class CPLErr, num CPLErrorNum, msg string) {
+		classes = append(classes, class)
+		messages = append(messages, msg)
+	})
+	defer SetErrorHandler(nil)
+
+	drv, err := GetDriverByName("GTiff")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if err = drv.DeleteDataset("/nonexistent/gdal_go_test.tif"); err == nil {
+		t.Fatalf("expected an error deleting a missing dataset")
+	}
+	if len(classes) == 0 || classes[len(classes)-1] != CE_Failure {
+		t.Fatalf("handler did not receive the failure: %v %v", classes, messages)
+	}
+}
+
+func TestConcurrentErrors(t *testing.T) {
+	drv, err := GetDriverByName("GTiff")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := fmt.Sprintf("/nonexistent/gdal_go_test_%d.tif", i)
+			for j := 0; j < 50; j++ {
+				err := drv.DeleteDataset(name)
+				if err == nil || !strings.Contains(err.Error(), name) {
+					t.Errorf("expected error about %s, got: %v", name, err)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestCreateError(t *testing.T) {
+	drv, err := GetDriverByName("GTiff")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	ds, err := drv.Create("/nonexistent/gdal_go_test.tif", 10, 10, 1, Byte, nil)
+	if err == nil {
+		ds.Close()
+		t.Fatalf("expected an error creating a dataset in a missing directory")
+	}
+	if !ds.IsNil() {
+		t.Errorf("expected a nil dataset")
+	}
+	if _, err := OpenDataSource("/nonexistent/gdal_go_test.shp", 0); err == nil {
+		t.Errorf("expected an error opening a missing data source")
+	}
+}
+
+func TestProgressFromContext(t *testing.T) {
+	src := newMemDataset(t, 10, 10, 1, Byte)
+	dst := newMemDataset(t, 10, 10, 1, Byte)
+
+	if err := src.CopyWholeRasterContext(context.Background(), dst, nil, nil, nil); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := src.CopyWholeRasterContext(ctx, dst, nil, nil, nil)
+	if !errors.Is(err, context.Canceled) || !errors.Is(err, ErrFailure) {
+		t.Errorf("expected a cancellation error, got: %+v", err)
+	}
+
+	// A failure before any progress is reported is not the context's doing
+	small := newMemDataset(t, 5, 5, 1, Byte)
+	err = src.CopyWholeRasterContext(ctx, small, nil, nil, nil)
+	if err == nil || errors.Is(err, context.Canceled) {
+		t.Errorf("expected a size mismatch error, got: %+v", err)
+	}
+}
+
+func TestScaledProgress(t *testing.T) {
+	var reported []float64
+	progress := func(complete float64, message string, data interface{}) int {
+		reported = append(reported, complete)
+		return 1
+	}
+
+	scaled := CreateScaledProgress(0.5, 1, progress, nil)
+	ScaledProgress(0.5, "", scaled)
+
+	steps := SplitProgress(progress, 1, 3)
+	steps[0](1, "", nil)
+	steps[1](0.5, "", nil)
+	steps[1](1, "", nil)
+
+	expected := []float64{0.75, 0.25, 0.625, 1}
+	if len(reported) != len(expected) {
+		t.Fatalf("got %v, expected %v", reported, expected)
+	}
+	for i := range expected {
+		if reported[i] != expected[i] {
+			t.Errorf("got %v, expected %v", reported, expected)
+		}
+	}
+}
+
+func TestProgressCallback(t *testing.T) {
+	src := newMemDataset(t, 10, 10, 1, Byte)
+	dst := newMemDataset(t, 10, 10, 1, Byte)
+
+	calls := 0
+	progress := func(complete float64, message string, data interface{}) int {
+		if data.(string) != "data" {
+			t.Errorf("unexpected progress data: %v", data)
+		}
+		calls++
+		return 1
+	}
+	if err := src.CopyWholeRaster(dst, nil, progress, "data"); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if calls == 0 {
+		t.Errorf("progress function was never called")
+	}
+	err := src.RasterBand(1).RasterBandCopyWholeRaster(dst.RasterBand(1), nil, nil, nil)
+	if err != nil {
+		t.Errorf("%+v", err)
+	}
+}
+
+func TestTypedRegionIO(t *testing.T) {
+	ds := newMemDataset(t, 4, 4, 1, Byte)
+	band := ds.RasterBand(1)
+
+	in := make([]float64, 16)
+	for i := range in {
+		in[i] = float64(i * 10)
+	}
+	if err := WriteRegion(band, 0, 0, 4, 4, in, 4, 4); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	out := make([]complex128, 16)
+	if err := ReadRegion(band, 0, 0, 4, 4, out, 4, 4); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	for i := range in {
+		if out[i] != complex(in[i], 0) {
+			t.Errorf("pixel %d: got %v, want %v", i, out[i], in[i])
+		}
+	}
+	if err := ReadRegion(band, 0, 0, 4, 4, make([]uint16, 15), 4, 4); err == nil {
+		t.Errorf("expected error for undersized buffer")
+	}
+	if dt := DataTypeOf[complex64](); dt != CFloat32 {
+		t.Errorf("DataTypeOf[complex64]() = %v, want CFloat32", dt)
+	}
+}
+
+func TestIOValidation(t *testing.T) {
+	ds := newMemDataset(t, 8, 8, 2, Byte)
+	band := ds.RasterBand(1)
+
+	cases := map[string]error{
+		"empty buffer": band.IO(Read, 0, 0, 8, 8, []uint8{}, 8, 8, 0, 0),
+		"short buffer": band.IO(Read, 0, 0, 8, 8, make([]uint8, 63), 8, 8, 0, 0),
+		"window":       band.IO(Read, 4, 4, 8, 8, make([]uint8, 64), 8, 8, 0, 0),
+		"band map":     ds.IO(Read, 0, 0, 8, 8, make([]uint8, 128), 8, 8, 2, []int{1}, 0, 0, 0),
+		"band range":   ds.IO(Read, 0, 0, 8, 8, make([]uint8, 128), 8, 8, 2, []int{1, 3}, 0, 0, 0),
+		"short bands":  ds.IO(Read, 0, 0, 8, 8, make([]uint8, 127), 8, 8, 2, []int{1, 2}, 0, 0, 0),
+		"no bands":     ds.IO(Read, 0, 0, 8, 8, make([]uint8, 128), 8, 8, 0, nil, 0, 0, 0),
+		"nil band map": ds.IO(Read, 0, 0, 8, 8, make([]uint8, 192), 8, 8, 3, nil, 0, 0, 0),
+		"block index":  ReadBlockSlice(band, 0, 8, make([]uint8, 64)),
+		"block type":   ReadBlockSlice(band, 0, 0, make([]float32, 64)),
+		"signed bytes": band.IO(Read, 0, 0, 8, 8, make([]int8, 64), 8, 8, 0, 0),
+	}
+	for name, err := range cases {
+		if err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	if err := ds.IO(Read, 0, 0, 8, 8, make([]uint8, 128), 8, 8, 2, []int{1, 2}, 0, 0, 0); err != nil {
+		t.Errorf("%+v", err)
+	}
+	if err := ds.IO(Read, 0, 0, 8, 8, make([]uint8, 128), 8, 8, 2, nil, 0, 0, 0); err != nil {
+		t.Errorf("nil band map: %+v", err)
+	}
+	xSize, ySize := band.BlockSize()
+	if err := ReadBlockSlice(band, 0, 0, make([]uint8, xSize*ySize)); err != nil {
+		t.Errorf("%+v", err)
+	}
+}
+
+func TestBlocks(t *testing.T) {
+	ds := newMemDataset(t, 10, 7, 1, Int16)
+	band := ds.RasterBand(1)
+	values := make([]int16, 70)
+	for i := range values {
+		values[i] = int16(i)
+	}
+	if err := WriteRegion(band, 0, 0, 10, 7, values, 10, 7); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	pixels := 0
+	for block, err := range Blocks[int16](band, 1) {
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+		w := block.Window
+		xSize, ySize, err := band.ActualBlockSize(block.XBlock, block.YBlock)
+		if err != nil || xSize != w.XSize || ySize != w.YSize {
+			t.Errorf("block %d,%d: actual size %dx%d, window %+v", block.XBlock, block.YBlock, xSize, ySize, w)
+		}
+		for y := block.DataWindow.YOff; y < block.DataWindow.YOff+block.DataWindow.YSize; y++ {
+			for x := block.DataWindow.XOff; x < block.DataWindow.XOff+block.DataWindow.XSize; x++ {
+				if got := block.At(x, y); got != int16(y*10+x) {
+					t.Fatalf("pixel %d,%d: got %d", x, y, got)
+				}
+			}
+		}
+		pixels += w.XSize * w.YSize
+	}
+	if pixels != 70 {
+		t.Errorf("blocks covered %d pixels, want 70", pixels)
+	}
+}
+
+func TestProcessBlocks(t *testing.T) {
+	ds := newMemDataset(t, 33, 21, 3, Float32)
+	a, b, dst := ds.RasterBand(1), ds.RasterBand(2), ds.RasterBand(3)
+	values := make([]float32, 33*21)
+	for i := range values {
+		values[i] = float32(i)
+	}
+	if err := WriteRegion(a, 0, 0, 33, 21, values, 33, 21); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if err := WriteRegion(b, 0, 0, 33, 21, values, 33, 21); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	sum := func(w Window, in [][]float32, out []float32) error {
+		for i := range out {
+			out[i] = in[0][i] + in[1][i]
+		}
+		return nil
+	}
+	if err := ProcessBlocks(context.Background(), []RasterBand{a, b}, dst, 4, sum); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	out := make([]float32, 33*21)
+	if err := ReadRegion(dst, 0, 0, 33, 21, out, 33, 21); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	for i := range out {
+		if out[i] != 2*values[i] {
+			t.Fatalf("pixel %d: got %v, want %v", i, out[i], 2*values[i])
+		}
+	}
+
+	failure := errors.New("failure")
+	fail := func(Window, [][]float32, []float32) error { return failure }
+	if err := ProcessBlocks(context.Background(), []RasterBand{a}, dst, 2, fail); !errors.Is(err, failure) {
+		t.Errorf("got %v, want %v", err, failure)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := ProcessBlocks(ctx, []RasterBand{a}, dst, 2, sum); !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestHandleOwnership(t *testing.T) {
+	var _ io.Closer = Dataset{}
+	var _ io.Closer = Geometry{}
+
+	ds := newMemDataset(t, 4, 4, 1, Byte)
+	band := ds.RasterBand(1)
+	owner := band.GetDataset()
+	if owner.cval != ds.cval {
+		t.Errorf("GetDataset did not return the owning dataset")
+	}
+	// Closing the borrowed dataset leaves the band usable
+	if err := owner.Close(); err != nil {
+		t.Errorf("closing borrowed dataset: %+v", err)
+	}
+	if err := ReadRegion(band, 0, 0, 4, 4, make([]uint8, 16), 4, 4); err != nil {
+		t.Errorf("read after closing borrowed dataset: %+v", err)
+	}
+	copied := ds
+	if err := ds.Close(); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if err := copied.Close(); err != nil {
+		t.Errorf("second close: %+v", err)
+	}
+	if !copied.IsNil() || !owner.IsNil() {
+		t.Errorf("closed dataset is not nil")
+	}
+
+	feature := CreateFeatureDefinition("test").Create()
+	defer feature.Close()
+	point := Create(GT_Point)
+	point.AddPoint2D(1, 2)
+	if err := feature.SetGeometryDirectly(point); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	// The geometry now belongs to the feature and must not be destroyed
+	point.Close()
+	borrowed := feature.Geometry()
+	borrowed.Destroy()
+	if x := feature.Geometry().X(0); x != 1 {
+		t.Errorf("feature geometry X = %v, want 1", x)
+	}
+	// Borrowed values become nil with their owner
+	feature.Close()
+	if !borrowed.IsNil() || !point.IsNil() {
+		t.Errorf("geometries of a closed feature are not nil")
+	}
+}
+
+type recordingTB struct {
+	errors []string
+}
+
+func (tb *recordingTB) Helper() {}
+
+func (tb *recordingTB) Errorf(format string, args ...interface{}) {
+	tb.errors = append(tb.errors, fmt.Sprintf(format, args...))
+}
+
+func TestHandleTracking(t *testing.T) {
+	EnableHandleTracking(true)
+	defer EnableHandleTracking(false)
+
+	leaked := Create(GT_Point)
+	closed := Create(GT_LineString)
+	closed.Close()
+
+	tb := &recordingTB{}
+	AssertNoLeaks(tb)
+	if len(tb.errors) != 1 || !strings.Contains(tb.errors[0], "TestHandleTracking") {
+		t.Errorf("unexpected leak report: %q", tb.errors)
+	}
+
+	leaked.Close()
+	AssertNoLeaks(t)
+}
+
+func TestHandleFinalizer(t *testing.T) {
+	destroyed := make(chan struct{})
+	ownHandle("test", 1, func(int) error {
+		close(destroyed)
+		return nil
+	})
+	for i := 0; i < 10; i++ {
+		runtime.GC()
+		select {
+		case <-destroyed:
+			return
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	t.Errorf("unreachable handle was not destroyed")
+}
+
+func TestSpatialReferenceRelease(t *testing.T) {
+	sr := CreateSpatialReference("")
+	if count := sr.Reference(); count != 2 {
+		t.Fatalf("reference count %d, want 2", count)
+	}
+	// Drops the owned reference and closes sr
+	sr.Release()
+	if !sr.IsNil() {
+		t.Errorf("released spatial reference is not nil")
+	}
+	if count := sr.Reference(); count != 2 {
+		t.Fatalf("reference count %d after release, want 2", count)
+	}
+	// Drops references taken with Reference
+	sr.Release()
+	if count := sr.Reference(); count != 2 {
+		t.Fatalf("reference count %d after second release, want 2", count)
+	}
+	sr.Release()
+	sr.Release()
+}
+
+func TestOpenDatasets(t *testing.T) {
+	drv, err := GetDriverByName("MEM")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	ds, err := drv.Create("listed", 3, 2, 1, Byte, nil)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	defer ds.Close()
+
+	found := false
+	for _, open := range OpenDatasets() {
+		if open.cval == ds.cval {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("dataset missing from OpenDatasets")
+	}
+
+	var buf strings.Builder
+	if err := DumpOpenDatasets(&buf); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if !strings.Contains(buf.String(), "MEM    3x2x1 listed") {
+		t.Errorf("unexpected dump:\n%s", buf.String())
+	}
+}
+
+func TestOpenEx(t *testing.T) {
+	if VERSION_MAJOR < 2 {
+		t.Skip("OpenEx requires GDAL 2.0")
+	}
+	name := t.TempDir() + "/points.shp"
+	ds, ok := OGRDriverByName("ESRI Shapefile").Create(name, nil)
+	if !ok {
+		t.Fatalf("cannot create %s", name)
+	}
+	ds.CreateLayer("points", SpatialReference{}, GT_Point, nil)
+	ds.Close()
+
+	_, err := OpenEx(name, OpenOptions{Raster: true, AllowedDrivers: []string{"GTiff"}})
+	if err == nil {
+		t.Errorf("expected an error opening a shapefile as GTiff raster")
+	}
+	dataset, err := OpenEx(name, OpenOptions{
+		Vector:         true,
+		AllowedDrivers: []string{"ESRI Shapefile"},
+	})
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	defer dataset.Close()
+	if count := dataset.DataSource().LayerCount(); count != 1 {
+		t.Errorf("got %d layers, want 1", count)
+	}
+}
+
+func TestValidateCreationOptions(t *testing.T) {
+	drv, err := GetDriverByName("GTiff")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	defs, err := drv.CreationOptions()
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	found := false
+	for _, def := range defs {
+		if def.Name == "COMPRESS" && def.Type == "string-select" && len(def.Values) > 0 {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("COMPRESS missing from %+v", defs)
+	}
+
+	opts := CreationOptions{}.Set("COMPRESS", "DEFLATE").Set("TILED", true).Set("BLOCKXSIZE", 256)
+	if got := strings.Join(opts.Strings(), ","); got != "BLOCKXSIZE=256,COMPRESS=DEFLATE,TILED=YES" {
+		t.Errorf("unexpected options %s", got)
+	}
+	if err := drv.ValidateCreationOptions(opts.Strings()); err != nil {
+		t.Errorf("%+v", err)
+	}
+	err = drv.ValidateCreationOptions([]string{"COMPRESS=DEFALTE", "TILD=YES"})
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	for _, want := range []string{"COMPRESS=DEFALTE", "unknown option TILD"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+}
+
+func TestDriverCapabilities(t *testing.T) {
+	drv, err := GetDriverByName("GTiff")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	caps := drv.Capabilities()
+	if !caps.Raster || !caps.Create || !caps.CreateCopy || caps.MIMEType != "image/tiff" {
+		t.Errorf("unexpected GTiff capabilities %+v", caps)
+	}
+	hasFloat32 := false
+	for _, dataType := range caps.CreationDataTypes {
+		hasFloat32 = hasFloat32 || dataType == Float32
+	}
+	if !hasFloat32 {
+		t.Errorf("Float32 missing from %v", caps.CreationDataTypes)
+	}
+
+	shapefile := false
+	for _, caps := range Drivers() {
+		if caps.ShortName == "ESRI Shapefile" && caps.Vector {
+			shapefile = true
+		}
+	}
+	if !shapefile {
+		t.Errorf("ESRI Shapefile missing from Drivers()")
+	}
+}
+
+func TestDatasetLayers(t *testing.T) {
+	if VERSION_MAJOR < 2 {
+		t.Skip("vector datasets require GDAL 2.0")
+	}
+	drv, err := GetDriverByName("Memory")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	ds, err := drv.Create("", 0, 0, 0, Unknown, nil)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	defer ds.Close()
+
+	layer := ds.CreateLayer("points", SpatialReference{}, GT_Point, nil)
+	if layer.IsNil() {
+		t.Fatalf("CreateLayer failed")
+	}
+	feature := layer.Definition().Create()
+	defer feature.Close()
+	point := Create(GT_Point)
+	point.AddPoint2D(3, 4)
+	feature.SetGeometryDirectly(point)
+	if err := layer.Create(feature); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	if count := ds.LayerCount(); count != 1 {
+		t.Errorf("got %d layers, want 1", count)
+	}
+	if name := ds.LayerByIndex(0).Name(); name != "points" {
+		t.Errorf("got layer %q, want points", name)
+	}
+	result := ds.ExecuteSQL("SELECT * FROM points", Geometry{}, "")
+	if count, _ := result.FeatureCount(true); count != 1 {
+		t.Errorf("got %d features, want 1", count)
+	}
+	ds.ReleaseResultSet(result)
+
+	// The DataSource methods are wrappers around the dataset ones
+	if layer := ds.DataSource().LayerByName("points"); layer.IsNil() {
+		t.Errorf("DataSource.LayerByName failed")
+	}
+}
+
+func TestVersion(t *testing.T) {
+	if RuntimeVersion() == "" {
+		t.Errorf("RuntimeVersion is empty")
+	}
+	if !CheckVersion(1, 0) {
+		t.Errorf("CheckVersion(1, 0) is false for %s", RuntimeVersion())
+	}
+	if CheckVersion(99, 0) {
+		t.Errorf("CheckVersion(99, 0) is true for %s", RuntimeVersion())
+	}
+	if major, minor := parseVersion("3.1.0dev"); major != 3 || minor != 1 {
+		t.Errorf("parsed 3.1.0dev as %d.%d", major, minor)
+	}
+
+	point := Create(GT_Point)
+	defer point.Close()
+	point.AddPoint2D(1, 2)
+	if VERSION_MAJOR < 3 {
+		_, err := point.MakeValid()
+		if !errors.Is(err, ErrNotSupported) || !errors.Is(err, ErrFailure) {
+			t.Errorf("%v does not match ErrNotSupported and ErrFailure", err)
+		}
+	}
+	onSurface, err := point.PointOnSurface()
+	if VERSION_MAJOR < 2 {
+		if !errors.Is(err, ErrNotSupported) {
+			t.Errorf("PointOnSurface: got %v, want ErrNotSupported", err)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	defer onSurface.Close()
+	if x, y, _ := onSurface.Point(0); x != 1 || y != 2 {
+		t.Errorf("PointOnSurface returned (%g, %g)", x, y)
+	}
+}
+
+func TestLinkedVersion(t *testing.T) {
+	major, minor := parseVersion(RuntimeVersion())
+	if major != VERSION_MAJOR || minor != VERSION_MINOR {
+		t.Errorf(
+			"linked GDAL %s does not match headers %d.%d",
+			RuntimeVersion(), VERSION_MAJOR, VERSION_MINOR,
+		)
+	}
+}
+
+func TestVSI(t *testing.T) {
+	if err := VSIMkdir("/vsimem/vsitest", 0755); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	defer VSIUnlink("/vsimem/vsitest")
+	name := "/vsimem/vsitest/data.bin"
+	if err := VSIFileFromMem(name, []byte("hello world")); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	stat, err := VSIStatL(name)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if stat.Size != 11 || stat.IsDir() {
+		t.Errorf("got %+v", stat)
+	}
+	names, err := VSIReadDir("/vsimem/vsitest")
+	if err != nil || len(names) != 1 || names[0] != "data.bin" {
+		t.Errorf("VSIReadDir returned %v, %v", names, err)
+	}
+
+	file, err := VSIFOpenL(name, "r+b")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if _, err := file.Seek(-5, io.SeekEnd); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if _, err := io.WriteString(file, "gdal!"); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	data, err := io.ReadAll(file)
+	if err != nil || string(data) != "hello gdal!" {
+		t.Errorf("read %q, %v", data, err)
+	}
+	if err := file.Close(); err != nil {
+		t.Errorf("%+v", err)
+	}
+	if _, err := file.Read(data); !errors.Is(err, fs.ErrClosed) {
+		t.Errorf("Read after Close returned %v", err)
+	}
+
+	data, err = VSIGetMemFileBuffer(name, true)
+	if err != nil || string(data) != "hello gdal!" {
+		t.Errorf("VSIGetMemFileBuffer returned %q, %v", data, err)
+	}
+	if _, err := VSIStatL(name); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("file still exists after unlink: %v", err)
+	}
+	if err := VSIUnlink(name); err == nil {
+		t.Errorf("unlinking a missing file succeeded")
+	}
+
+	empty := "/vsimem/vsitest/empty.bin"
+	if err := VSIFileFromMem(empty, nil); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if stat, err := VSIStatL(empty); err != nil || stat.Size != 0 {
+		t.Errorf("VSIStatL returned %+v, %v", stat, err)
+	}
+	data, err = VSIGetMemFileBuffer(empty, true)
+	if err != nil || len(data) != 0 {
+		t.Errorf("VSIGetMemFileBuffer returned %q, %v", data, err)
+	}
+}
+
+func TestRegisterVSIHandler(t *testing.T) {
+	drv, err := GetDriverByName("GTiff")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	src, err := drv.Create("/vsimem/vsihandler.tif", 4, 3, 1, Byte, nil)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	data := []uint8{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11}
+	if err := WriteRegion(src.RasterBand(1), 0, 0, 4, 3, data, 4, 3); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	src.Close()
+	tiff, err := VSIGetMemFileBuffer("/vsimem/vsihandler.tif", true)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	fsys := fstest.MapFS{"dir/a.tif": &fstest.MapFile{Data: tiff}}
+	err = RegisterVSIHandler("/vsigotest/", fsys)
+	if VERSION_MAJOR < 3 {
+		if !errors.Is(err, ErrNotSupported) {
+			t.Errorf("got %v, want ErrNotSupported", err)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if err := RegisterVSIHandler("/vsigotest/", fsys); err == nil {
+		t.Errorf("registering a prefix twice succeeded")
+	}
+
+	stat, err := VSIStatL("/vsigotest/dir/a.tif")
+	if err != nil || stat.Size != int64(len(tiff)) {
+		t.Errorf("VSIStatL returned %+v, %v", stat, err)
+	}
+	names, err := VSIReadDir("/vsigotest/dir")
+	if err != nil || len(names) != 1 || names[0] != "a.tif" {
+		t.Errorf("VSIReadDir returned %v, %v", names, err)
+	}
+
+	ds, err := Open("/vsigotest/dir/a.tif", ReadOnly)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	defer ds.Close()
+	got := make([]uint8, 12)
+	if err := ReadRegion(ds.RasterBand(1), 0, 0, 4, 3, got, 4, 3); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	for i := range data {
+		if got[i] != data[i] {
+			t.Fatalf("read %v, want %v", got, data)
+		}
+	}
+}
+
+func TestArchive(t *testing.T) {
+	dir := t.TempDir()
+	ds, ok := OGRDriverByName("ESRI Shapefile").Create(dir+"/points.shp", nil)
+	if !ok {
+		t.Fatalf("cannot create %s/points.shp", dir)
+	}
+	ds.CreateLayer("points", SpatialReference{}, GT_Point, nil)
+	ds.Close()
+
+	names, err := VSIReadDir(dir)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	var files []string
+	for _, name := range names {
+		files = append(files, dir+"/"+name)
+	}
+	if err := VSIFileFromMem("/vsimem/readme.txt", []byte("points")); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	defer VSIUnlink("/vsimem/readme.txt")
+	files = append(files, "/vsimem/readme.txt")
+
+	archive := dir + "/points.zip"
+	if err := WriteZip(archive, files); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if !IsArchive(archive) || IsArchive(dir+"/points.shp") {
+		t.Errorf("IsArchive misdetected the zip or the shapefile")
+	}
+	members, err := ArchiveMembers(archive)
+	if err != nil || len(members) != len(files) {
+		t.Errorf("ArchiveMembers returned %v, %v", members, err)
+	}
+	name, err := ArchiveDatasetPath(archive)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if want := "/vsizip/" + archive + "/points.shp"; name != want {
+		t.Errorf("got %s, want %s", name, want)
+	}
+
+	source, err := OpenArchiveDataSource(archive, 0)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	defer source.Close()
+	if count := source.LayerCount(); count != 1 {
+		t.Errorf("got %d layers, want 1", count)
+	}
+}
+
+func TestImage(t *testing.T) {
+	gray := image.NewGray(image.Rect(0, 0, 4, 3))
+	for i := range gray.Pix {
+		gray.Pix[i] = uint8(i * 10)
+	}
+	ds, err := NewDatasetFromImage(gray, "MEM")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	defer ds.Close()
+	img, err := ds.ToImage(Window{XOff: 1, YOff: 1, XSize: 2, YSize: 2})
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if got, ok := img.(*image.Gray); !ok || got.GrayAt(1, 1).Y != 100 {
+		t.Errorf("got %T %v, want *image.Gray with 100 at (1, 1)", img, img.At(1, 1))
+	}
+
+	palette := color.Palette{color.NRGBA{255, 0, 0, 255}, color.NRGBA{0, 0, 255, 255}}
+	paletted := image.NewPaletted(image.Rect(0, 0, 2, 2), palette)
+	paletted.SetColorIndex(1, 0, 1)
+	ds, err = NewDatasetFromImage(paletted, "MEM")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	defer ds.Close()
+	img, err = ds.ToImage(Window{})
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if got, ok := img.(*image.Paletted); !ok || len(got.Palette) != 2 || got.ColorIndexAt(1, 0) != 1 {
+		t.Errorf("got %T, want *image.Paletted with index 1 at (1, 0)", img)
+	}
+	// Indexes past the color table get transparent entries
+	if err := WriteRegion(ds.RasterBand(1), 0, 1, 1, 1, []uint8{5}, 1, 1); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	img, err = ds.ToImage(Window{})
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if _, _, _, a := img.At(0, 1).RGBA(); a != 0 || len(img.(*image.Paletted).Palette) != 6 {
+		t.Errorf("index 5 beyond a 2 entry color table was not padded")
+	}
+
+	ds = newMemDataset(t, 1, 1, 2, Byte)
+	alphaBand, grayBand := ds.RasterBand(1), ds.RasterBand(2)
+	if err := alphaBand.SetColorInterp(CI_AlphaBand); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if err := grayBand.SetColorInterp(CI_GrayIndex); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if err := alphaBand.Fill(255, 0); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if err := grayBand.Fill(100, 0); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	img, err = ds.ToImage(Window{})
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if got := color.NRGBAModel.Convert(img.At(0, 0)); got != (color.NRGBA{100, 100, 100, 255}) {
+		t.Errorf("alpha then gray bands: got %v, want gray 100", got)
+	}
+
+	rgba := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	rgba.SetNRGBA(0, 0, color.NRGBA{10, 20, 30, 255})
+	rgba.SetNRGBA(1, 0, color.NRGBA{200, 100, 50, 0})
+	ds, err = NewDatasetFromImage(rgba, "MEM")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	defer ds.Close()
+	if count := ds.RasterCount(); count != 4 {
+		t.Fatalf("got %d bands, want 4", count)
+	}
+	img, err = ds.ToImage(Window{})
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if got := color.NRGBAModel.Convert(img.At(0, 0)); got != (color.NRGBA{10, 20, 30, 255}) {
+		t.Errorf("got %v at (0, 0)", got)
+	}
+	if _, _, _, a := img.At(1, 0).RGBA(); a != 0 {
+		t.Errorf("pixel (1, 0) is not transparent")
+	}
+}
+
+func TestStatistics(t *testing.T) {
+	ds := newMemDataset(t, 10, 10, 1, Byte)
+	if err := ds.SetGeoTransform([6]float64{0, 1, 0, 10, 0, -1}); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	band := ds.RasterBand(1)
+	data := make([]uint8, 100)
+	for i := range data {
+		data[i] = uint8(i)
+	}
+	if err := WriteRegion(band, 0, 0, 10, 10, data, 10, 10); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if err := band.SetNoDataValue(0); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	stats, err := band.Statistics(StatisticsOptions{})
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if stats.ValidCount != 99 || stats.NoDataCount != 1 || stats.Min != 1 || stats.Max != 99 ||
+		math.Abs(stats.Mean-50) > 1e-9 {
+		t.Errorf("got %+v", stats)
+	}
+	if fraction := stats.ValidFraction(); math.Abs(fraction-0.99) > 1e-9 {
+		t.Errorf("got valid fraction %g, want 0.99", fraction)
+	}
+
+	stats, err = band.Statistics(StatisticsOptions{Window: Window{XOff: 5, YOff: 5, XSize: 2, YSize: 2}})
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if stats.ValidCount != 4 || stats.Min != 55 || stats.Max != 66 {
+		t.Errorf("got %+v", stats)
+	}
+
+	// The top left 5x5 pixels, including the nodata pixel
+	square, err := CreateFromWKT("POLYGON ((0 5,5 5,5 10,0 10,0 5))", SpatialReference{})
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	defer square.Close()
+	stats, err = band.Statistics(StatisticsOptions{Geometry: square})
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if stats.ValidCount != 24 || stats.NoDataCount != 1 || stats.Max != 44 {
+		t.Errorf("got %+v", stats)
+	}
+
+	if sample, err := band.RandomRasterSample(10); err != nil || len(sample) == 0 || len(sample) > 10 {
+		t.Errorf("got %d samples, %v", len(sample), err)
+	}
+	min, max, mean, _, err := band.ComputeRasterStatistics(false, nil, nil)
+	if err != nil || min != 1 || max != 99 || math.Abs(mean-50) > 1e-9 {
+		t.Errorf("ComputeRasterStatistics returned %g, %g, %g, %v", min, max, mean, err)
+	}
+	if _, _, _, _, err := band.RasterStatistics(false, false); err != nil {
+		t.Errorf("computed statistics are not cached: %v", err)
+	}
+	if min, max, err := band.ComputeRasterMinMax(false); err != nil || min != 1 || max != 99 {
+		t.Errorf("ComputeRasterMinMax returned %g, %g, %v", min, max, err)
+	}
+	if _, _, err := band.ComputeBandStats(1, DummyProgress, nil); err != nil {
+		t.Errorf("%+v", err)
+	}
+}
@@ -0,0 +1,3 @@
+module github.com/lukeroth/gdal
+
+go 1.23
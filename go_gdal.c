@@ -5,15 +5,17 @@
 #include "go_gdal.h"
 #include "_cgo_export.h"
 
+#include <string.h>
+
 #include <cpl_conv.h>
 
-static int goGDALProgressFuncProxyB_(
+static int CPL_STDCALL goGDALProgressFuncProxyB_(
 	double complete, 
 	const char *message, 
 	void *progressArg
 ) {
-	GoInterface* args = (GoInterface*)progressArg;
-	int returnVal = goGDALProgressFuncProxyA(complete, (char*)message, args);
+	uintptr_t handle = (uintptr_t)progressArg;
+	int returnVal = goGDALProgressFuncProxyA(complete, (char*)message, handle);
 	return (int)returnVal;
 }
 
@@ -21,4 +23,331 @@ GDALProgressFunc goGDALProgressFuncProxyB() {
 	return goGDALProgressFuncProxyB_;
 }
 
+void *goGDALProgressArg(uintptr_t handle) {
+	return (void*)handle;
+}
+
+static void CPL_STDCALL goCPLErrorHandlerProxyB_(
+	CPLErr errClass,
+	int errNum,
+	const char *message
+) {
+	goCPLErrorHandlerProxyA((int)errClass, errNum, (char*)message);
+}
+
+CPLErrorHandler goCPLErrorHandlerProxyB() {
+	return goCPLErrorHandlerProxyB_;
+}
+
+
+
+GDALDatasetH goGDALOpenEx(
+	const char *filename,
+	unsigned int flags,
+	char **allowedDrivers,
+	char **openOptions,
+	char **siblingFiles
+) {
+#if GDAL_VERSION_NUM >= GDAL_COMPUTE_VERSION(2, 0, 0)
+	return GDALOpenEx(
+		filename,
+		flags,
+		(const char *const *)allowedDrivers,
+		(const char *const *)openOptions,
+		(const char *const *)siblingFiles
+	);
+#else
+	CPLError(CE_Failure, CPLE_NotSupported, "GDALOpenEx requires GDAL 2.0 or later");
+	return NULL;
+#endif
+}
+
+#if GDAL_VERSION_NUM >= GDAL_COMPUTE_VERSION(2, 0, 0)
+
+int goDatasetLayerCount(GDALDatasetH ds) {
+	return GDALDatasetGetLayerCount(ds);
+}
+
+OGRLayerH goDatasetLayer(GDALDatasetH ds, int index) {
+	return GDALDatasetGetLayer(ds, index);
+}
+
+OGRLayerH goDatasetLayerByName(GDALDatasetH ds, const char *name) {
+	return GDALDatasetGetLayerByName(ds, name);
+}
+
+OGRErr goDatasetDeleteLayer(GDALDatasetH ds, int index) {
+	return GDALDatasetDeleteLayer(ds, index);
+}
+
+OGRLayerH goDatasetCreateLayer(
+	GDALDatasetH ds,
+	const char *name,
+	OGRSpatialReferenceH srs,
+	OGRwkbGeometryType geomType,
+	char **options
+) {
+	return GDALDatasetCreateLayer(ds, name, srs, geomType, options);
+}
+
+OGRLayerH goDatasetCopyLayer(
+	GDALDatasetH ds,
+	OGRLayerH source,
+	const char *name,
+	char **options
+) {
+	return GDALDatasetCopyLayer(ds, source, name, options);
+}
+
+int goDatasetTestCapability(GDALDatasetH ds, const char *capability) {
+	return GDALDatasetTestCapability(ds, capability);
+}
+
+OGRLayerH goDatasetExecuteSQL(
+	GDALDatasetH ds,
+	const char *sql,
+	OGRGeometryH filter,
+	const char *dialect
+) {
+	return GDALDatasetExecuteSQL(ds, sql, filter, dialect);
+}
+
+void goDatasetReleaseResultSet(GDALDatasetH ds, OGRLayerH layer) {
+	GDALDatasetReleaseResultSet(ds, layer);
+}
+
+#else
+
+// Before GDAL 2.0 a GDALDatasetH is a raster dataset only and must not be
+// passed to the OGR_DS_* functions
+static void goDatasetNotSupported(const char *function) {
+	CPLError(CE_Failure, CPLE_NotSupported, "%s requires GDAL 2.0 or later", function);
+}
+
+int goDatasetLayerCount(GDALDatasetH ds) {
+	goDatasetNotSupported("GDALDatasetGetLayerCount");
+	return 0;
+}
+
+OGRLayerH goDatasetLayer(GDALDatasetH ds, int index) {
+	goDatasetNotSupported("GDALDatasetGetLayer");
+	return NULL;
+}
+
+OGRLayerH goDatasetLayerByName(GDALDatasetH ds, const char *name) {
+	goDatasetNotSupported("GDALDatasetGetLayerByName");
+	return NULL;
+}
+
+OGRErr goDatasetDeleteLayer(GDALDatasetH ds, int index) {
+	goDatasetNotSupported("GDALDatasetDeleteLayer");
+	return OGRERR_UNSUPPORTED_OPERATION;
+}
+
+OGRLayerH goDatasetCreateLayer(
+	GDALDatasetH ds,
+	const char *name,
+	OGRSpatialReferenceH srs,
+	OGRwkbGeometryType geomType,
+	char **options
+) {
+	goDatasetNotSupported("GDALDatasetCreateLayer");
+	return NULL;
+}
+
+OGRLayerH goDatasetCopyLayer(
+	GDALDatasetH ds,
+	OGRLayerH source,
+	const char *name,
+	char **options
+) {
+	goDatasetNotSupported("GDALDatasetCopyLayer");
+	return NULL;
+}
+
+int goDatasetTestCapability(GDALDatasetH ds, const char *capability) {
+	return 0;
+}
+
+OGRLayerH goDatasetExecuteSQL(
+	GDALDatasetH ds,
+	const char *sql,
+	OGRGeometryH filter,
+	const char *dialect
+) {
+	goDatasetNotSupported("GDALDatasetExecuteSQL");
+	return NULL;
+}
+
+void goDatasetReleaseResultSet(GDALDatasetH ds, OGRLayerH layer) {
+}
+
+#endif
+
+#if GDAL_VERSION_NUM >= GDAL_COMPUTE_VERSION(2, 0, 0)
+
+OGRGeometryH goGeometryPointOnSurface(OGRGeometryH geom) {
+	return OGR_G_PointOnSurface(geom);
+}
+
+CPLErr goFeatureFieldAsInteger64(OGRFeatureH feature, int index, long long *value) {
+	*value = OGR_F_GetFieldAsInteger64(feature, index);
+	return CE_None;
+}
+
+CPLErr goFeatureSetFieldInteger64(OGRFeatureH feature, int index, long long value) {
+	OGR_F_SetFieldInteger64(feature, index, value);
+	return CE_None;
+}
+
+#else
+
+OGRGeometryH goGeometryPointOnSurface(OGRGeometryH geom) {
+	CPLError(CE_Failure, CPLE_NotSupported, "OGR_G_PointOnSurface requires GDAL 2.0 or later");
+	return NULL;
+}
+
+CPLErr goFeatureFieldAsInteger64(OGRFeatureH feature, int index, long long *value) {
+	*value = 0;
+	CPLError(CE_Failure, CPLE_NotSupported, "OGR_F_GetFieldAsInteger64 requires GDAL 2.0 or later");
+	return CE_Failure;
+}
+
+CPLErr goFeatureSetFieldInteger64(OGRFeatureH feature, int index, long long value) {
+	CPLError(CE_Failure, CPLE_NotSupported, "OGR_F_SetFieldInteger64 requires GDAL 2.0 or later");
+	return CE_Failure;
+}
+
+#endif
+
+#if GDAL_VERSION_NUM >= GDAL_COMPUTE_VERSION(3, 0, 0)
+
+OGRGeometryH goGeometryMakeValid(OGRGeometryH geom) {
+	return OGR_G_MakeValid(geom);
+}
+
+CPLErr goSRSSetAxisMappingStrategy(OGRSpatialReferenceH srs, int strategy) {
+	OSRSetAxisMappingStrategy(srs, (OSRAxisMappingStrategy)strategy);
+	return CE_None;
+}
+
+CPLErr goSRSAxisMappingStrategy(OGRSpatialReferenceH srs, int *strategy) {
+	*strategy = (int)OSRGetAxisMappingStrategy(srs);
+	return CE_None;
+}
+
+#else
+
+OGRGeometryH goGeometryMakeValid(OGRGeometryH geom) {
+	CPLError(CE_Failure, CPLE_NotSupported, "OGR_G_MakeValid requires GDAL 3.0 or later");
+	return NULL;
+}
+
+CPLErr goSRSSetAxisMappingStrategy(OGRSpatialReferenceH srs, int strategy) {
+	CPLError(CE_Failure, CPLE_NotSupported, "OSRSetAxisMappingStrategy requires GDAL 3.0 or later");
+	return CE_Failure;
+}
+
+CPLErr goSRSAxisMappingStrategy(OGRSpatialReferenceH srs, int *strategy) {
+	*strategy = 0;
+	CPLError(CE_Failure, CPLE_NotSupported, "OSRGetAxisMappingStrategy requires GDAL 3.0 or later");
+	return CE_Failure;
+}
+
+#endif
+
+int goVSIStat(
+	const char *filename,
+	long long *size,
+	long long *mtime,
+	int *mode,
+	int *isDir
+) {
+	VSIStatBufL stat;
+	int result = VSIStatL(filename, &stat);
+	if (result != 0) {
+		return result;
+	}
+	*size = (long long)stat.st_size;
+	*mtime = (long long)stat.st_mtime;
+	*mode = (int)(stat.st_mode & 0777);
+	*isDir = VSI_ISDIR(stat.st_mode) ? 1 : 0;
+	return 0;
+}
+
+void goCPLError(CPLErr errClass, int errNum, const char *message) {
+	CPLError(errClass, errNum, "%s", message);
+}
+
+#if GDAL_VERSION_NUM >= GDAL_COMPUTE_VERSION(3, 0, 0)
+
+static int goVSIStatProxy(
+	void *userData,
+	const char *filename,
+	VSIStatBufL *stat,
+	int flags
+) {
+	long long size, mtime;
+	int isDir;
+	if (goVSIHandlerStat((uintptr_t)userData, (char*)filename, &size, &mtime, &isDir) != 0) {
+		return -1;
+	}
+	memset(stat, 0, sizeof(*stat));
+	stat->st_size = size;
+	stat->st_mtime = mtime;
+	stat->st_mode = isDir ? S_IFDIR | 0555 : S_IFREG | 0444;
+	return 0;
+}
+
+static char **goVSIReadDirProxy(void *userData, const char *dirname, int maxFiles) {
+	return goVSIHandlerReadDir((uintptr_t)userData, (char*)dirname, maxFiles);
+}
+
+static void *goVSIOpenProxy(void *userData, const char *filename, const char *access) {
+	return (void*)goVSIHandlerOpen((uintptr_t)userData, (char*)filename, (char*)access);
+}
+
+static vsi_l_offset goVSITellProxy(void *file) {
+	return goVSIFileTell((uintptr_t)file);
+}
+
+static int goVSISeekProxy(void *file, vsi_l_offset offset, int whence) {
+	return goVSIFileSeek((uintptr_t)file, offset, whence);
+}
+
+static size_t goVSIReadProxy(void *file, void *buffer, size_t size, size_t count) {
+	return goVSIFileRead((uintptr_t)file, buffer, size, count);
+}
+
+static int goVSIEofProxy(void *file) {
+	return goVSIFileEOF((uintptr_t)file);
+}
+
+static int goVSICloseProxy(void *file) {
+	return goVSIFileClose((uintptr_t)file);
+}
+
+int goVSIInstallPluginHandler(const char *prefix, uintptr_t fsys) {
+	VSIFilesystemPluginCallbacksStruct *callbacks = VSIAllocFilesystemPluginCallbacksStruct();
+	callbacks->pUserData = (void*)fsys;
+	callbacks->stat = goVSIStatProxy;
+	callbacks->read_dir = goVSIReadDirProxy;
+	callbacks->open = goVSIOpenProxy;
+	callbacks->tell = goVSITellProxy;
+	callbacks->seek = goVSISeekProxy;
+	callbacks->read = goVSIReadProxy;
+	callbacks->eof = goVSIEofProxy;
+	callbacks->close = goVSICloseProxy;
+	int result = VSIInstallPluginHandler(prefix, callbacks);
+	VSIFreeFilesystemPluginCallbacksStruct(callbacks);
+	return result;
+}
+
+#else
+
+int goVSIInstallPluginHandler(const char *prefix, uintptr_t fsys) {
+	CPLError(CE_Failure, CPLE_NotSupported, "VSIInstallPluginHandler requires GDAL 3.0 or later");
+	return -1;
+}
 
+#endif
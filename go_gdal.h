@@ -5,15 +5,101 @@
 #ifndef GO_GDAL_H_
 #define GO_GDAL_H_
 
+#include <stdint.h>
+
 #include <gdal.h>
 #include <gdal_alg.h>
 #include <gdalwarper.h>
 #include <cpl_conv.h>
+#include <cpl_error.h>
+#include <ogr_api.h>
 #include <ogr_srs_api.h>
 
+// Functions missing from the GDAL headers in use are compiled as shims
+// failing with CPLE_NotSupported, selected on GDAL_VERSION_NUM
+#ifndef GDAL_COMPUTE_VERSION
+#define GDAL_COMPUTE_VERSION(maj, min, rev) ((maj)*1000000 + (min)*10000 + (rev)*100)
+#endif
+
+// Feature ids are long before GDAL 2.0 and GIntBig after
+#if GDAL_VERSION_NUM >= GDAL_COMPUTE_VERSION(2, 0, 0)
+typedef GIntBig goFID;
+#else
+typedef long goFID;
+#endif
+
 // transform GDALProgressFunc to go func
 GDALProgressFunc goGDALProgressFuncProxyB();
 
+// wrap a runtime/cgo.Handle as the argument of goGDALProgressFuncProxyB
+void *goGDALProgressArg(uintptr_t handle);
+
+// transform CPLErrorHandler to go func
+CPLErrorHandler goCPLErrorHandlerProxyB();
+
+// GDALOpenEx, failing with CPLE_NotSupported before GDAL 2.0
+GDALDatasetH goGDALOpenEx(
+	const char *filename,
+	unsigned int flags,
+	char **allowedDrivers,
+	char **openOptions,
+	char **siblingFiles
+);
+
+// Vector access on a dataset: the GDALDataset* functions with GDAL 2.0 or
+// later, failing with CPLE_NotSupported before that
+int goDatasetLayerCount(GDALDatasetH ds);
+OGRLayerH goDatasetLayer(GDALDatasetH ds, int index);
+OGRLayerH goDatasetLayerByName(GDALDatasetH ds, const char *name);
+OGRErr goDatasetDeleteLayer(GDALDatasetH ds, int index);
+OGRLayerH goDatasetCreateLayer(
+	GDALDatasetH ds,
+	const char *name,
+	OGRSpatialReferenceH srs,
+	OGRwkbGeometryType geomType,
+	char **options
+);
+OGRLayerH goDatasetCopyLayer(
+	GDALDatasetH ds,
+	OGRLayerH source,
+	const char *name,
+	char **options
+);
+int goDatasetTestCapability(GDALDatasetH ds, const char *capability);
+OGRLayerH goDatasetExecuteSQL(
+	GDALDatasetH ds,
+	const char *sql,
+	OGRGeometryH filter,
+	const char *dialect
+);
+void goDatasetReleaseResultSet(GDALDatasetH ds, OGRLayerH layer);
+
+// Functions added in GDAL 2.0
+OGRGeometryH goGeometryPointOnSurface(OGRGeometryH geom);
+CPLErr goFeatureFieldAsInteger64(OGRFeatureH feature, int index, long long *value);
+CPLErr goFeatureSetFieldInteger64(OGRFeatureH feature, int index, long long value);
+
+// Functions added in GDAL 3.0
+OGRGeometryH goGeometryMakeValid(OGRGeometryH geom);
+CPLErr goSRSSetAxisMappingStrategy(OGRSpatialReferenceH srs, int strategy);
+CPLErr goSRSAxisMappingStrategy(OGRSpatialReferenceH srs, int *strategy);
+
+// VSIStatL, unpacking the platform dependent VSIStatBufL
+int goVSIStat(
+	const char *filename,
+	long long *size,
+	long long *mtime,
+	int *mode,
+	int *isDir
+);
+
+// CPLError without the format string, for errors raised from Go
+void goCPLError(CPLErr errClass, int errNum, const char *message);
+
+// Install a VSI file system handler whose callbacks call into the Go fs.FS
+// behind a runtime/cgo.Handle, failing with CPLE_NotSupported before GDAL 3.0
+int goVSIInstallPluginHandler(const char *prefix, uintptr_t fsys);
+
 #endif // GO_GDAL_H_
 
 
@@ -0,0 +1,221 @@
+package gdal
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+/* --------------------------------------------- */
+/* Handle ownership                              */
+/* --------------------------------------------- */
+
+// handle is the ownership state shared by all copies of a Dataset,
+// DataSource, Geometry, Feature, SpatialReference, CoordinateTransform,
+// ColorTable, RasterAttributeTable or VSIFile, so closing any copy closes
+// them all and a second close is a no-op.
+//
+// An owned handle destroys its C object on Close, or from a finalizer once
+// no copy of the value is reachable. Methods hand only the C pointer to
+// GDAL, so each one keeps its handle alive with runtime.KeepAlive until the
+// C call returns. While EnableHandleTracking is on the tracker keeps every
+// owned handle reachable, so AssertNoLeaks reports objects that were never
+// closed instead of the finalizer hiding them.
+//
+// A borrowed handle refers to an object owned by someone else, such as the
+// geometry of a feature or the spatial reference of a layer. Closing it is a
+// no-op and it reports itself closed once its parent is. A nil *handle
+// behaves as borrowed.
+type handle struct {
+	mu      sync.Mutex
+	owned   bool
+	closed  bool
+	destroy func() error
+	parent  *handle
+	kind    string
+	stack   string
+}
+
+// ownHandle returns the state for a C object of the named kind that the
+// caller must destroy
+func ownHandle[H comparable](kind string, cval H, destroy func(H) error) *handle {
+	var zero H
+	if cval == zero {
+		return nil
+	}
+	h := &handle{
+		owned:   true,
+		destroy: func() error { return destroy(cval) },
+		kind:    kind,
+	}
+	trackHandle(h)
+	runtime.SetFinalizer(h, (*handle).close)
+	return h
+}
+
+// borrowHandle returns the state for a C object owned by parent
+func borrowHandle(parent *handle) *handle {
+	if parent == nil {
+		return nil
+	}
+	return &handle{parent: parent}
+}
+
+// close destroys the C object if the handle owns it. It is safe to call on
+// a nil handle and more than once.
+func (h *handle) close() error {
+	_, err := h.closeOwned()
+	return err
+}
+
+// closeOwned is close, also reporting whether the handle still owned the C
+// object
+func (h *handle) closeOwned() (bool, error) {
+	if h == nil {
+		return false, nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.owned {
+		return false, nil
+	}
+	h.owned = false
+	h.closed = true
+	untrackHandle(h)
+	return true, h.destroy()
+}
+
+// disown records that ownership of the C object moved to parent, for
+// example when a geometry is handed to a feature with SetGeometryDirectly
+func (h *handle) disown(parent *handle) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.owned {
+		h.owned = false
+		untrackHandle(h)
+	}
+	h.parent = parent
+}
+
+// consume records that GDAL destroyed the C object, for example when a
+// geometry is passed to OGR_G_ForceToPolygon, so it must not be used again
+func (h *handle) consume() {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.owned {
+		h.owned = false
+		untrackHandle(h)
+	}
+	h.closed = true
+}
+
+// isClosed reports whether the C object was destroyed through this handle
+// or through the parent it was borrowed from
+func (h *handle) isClosed() bool {
+	if h == nil {
+		return false
+	}
+	h.mu.Lock()
+	closed, parent := h.closed, h.parent
+	h.mu.Unlock()
+	return closed || parent.isClosed()
+}
+
+/* --------------------------------------------- */
+/* Leak detection                                */
+/* --------------------------------------------- */
+
+var tracker struct {
+	sync.Mutex
+	enabled bool
+	live    map[*handle]struct{}
+}
+
+// EnableHandleTracking turns recording of owned handles on or off. While
+// enabled, every Dataset, DataSource, Geometry, Feature, SpatialReference,
+// CoordinateTransform, ColorTable, RasterAttributeTable and VSIFile created
+// by the wrapper is recorded with the stack that created it until it is
+// closed or its ownership passes to another object. Disabling tracking
+// forgets all records.
+func EnableHandleTracking(enable bool) {
+	tracker.Lock()
+	defer tracker.Unlock()
+	tracker.enabled = enable
+	tracker.live = nil
+	if enable {
+		tracker.live = make(map[*handle]struct{})
+	}
+}
+
+// TrackedHandle describes an owned handle that has not been closed
+type TrackedHandle struct {
+	Kind  string
+	Stack string
+}
+
+// TrackedHandles returns the handles recorded since tracking was enabled
+// that are still open
+func TrackedHandles() []TrackedHandle {
+	tracker.Lock()
+	defer tracker.Unlock()
+	handles := make([]TrackedHandle, 0, len(tracker.live))
+	for h := range tracker.live {
+		handles = append(handles, TrackedHandle{Kind: h.kind, Stack: h.stack})
+	}
+	return handles
+}
+
+// TB is the subset of testing.TB used by AssertNoLeaks
+type TB interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// AssertNoLeaks reports an error on t for every tracked handle that is
+// still open. Typical use in a test:
+//
+//	gdal.EnableHandleTracking(true)
+//	defer gdal.EnableHandleTracking(false)
+//	...
+//	gdal.AssertNoLeaks(t)
+func AssertNoLeaks(t TB) {
+	t.Helper()
+	for _, h := range TrackedHandles() {
+		t.Errorf("leaked %s created at:\n%s", h.Kind, h.Stack)
+	}
+}
+
+func trackHandle(h *handle) {
+	tracker.Lock()
+	defer tracker.Unlock()
+	if !tracker.enabled {
+		return
+	}
+	// Skip runtime.Callers, trackHandle and ownHandle
+	pcs := make([]uintptr, 32)
+	pcs = pcs[:runtime.Callers(3, pcs)]
+	var stack strings.Builder
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&stack, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	h.stack = stack.String()
+	tracker.live[h] = struct{}{}
+}
+
+func untrackHandle(h *handle) {
+	tracker.Lock()
+	defer tracker.Unlock()
+	delete(tracker.live, h)
+}
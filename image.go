@@ -0,0 +1,320 @@
+package gdal
+
+/*
+#include "go_gdal.h"
+*/
+import "C"
+import (
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+)
+
+/* --------------------------------------------- */
+/* Go images                                     */
+/* --------------------------------------------- */
+
+// ToImage reads a window of the dataset into a Go image whose bounds start
+// at (0, 0). A zero window reads the whole raster. The image type follows
+// the bands:
+//
+//   - a paletted band with a color table of at most 256 entries gives an
+//     *image.Paletted. Pixel values beyond the color table get transparent
+//     palette entries.
+//   - a single Byte band gives an *image.Gray
+//   - a single band of any other type gives an *image.Gray16, with values
+//     clamped to the uint16 range
+//   - bands interpreted as red, green, blue and optionally alpha, or failing
+//     that the first three bands, give an *image.RGBA. Two bands, one of
+//     them alpha and the other gray, also give an *image.RGBA.
+func (dataset Dataset) ToImage(window Window) (image.Image, error) {
+	if window == (Window{}) {
+		window = Window{XSize: dataset.RasterXSize(), YSize: dataset.RasterYSize()}
+	}
+	count := dataset.RasterCount()
+	if count == 0 {
+		return nil, fmt.Errorf("dataset has no raster bands")
+	}
+	bounds := image.Rect(0, 0, window.XSize, window.YSize)
+	read := func(band RasterBand, buf []uint8) error {
+		return ReadRegion(
+			band,
+			window.XOff, window.YOff, window.XSize, window.YSize,
+			buf, window.XSize, window.YSize,
+		)
+	}
+
+	bands := map[ColorInterp]RasterBand{}
+	for i := 1; i <= count; i++ {
+		band := dataset.RasterBand(i)
+		bands[band.ColorInterp()] = band
+	}
+	first := dataset.RasterBand(1)
+	red, hasRed := bands[CI_RedBand]
+	green, hasGreen := bands[CI_GreenBand]
+	blue, hasBlue := bands[CI_BlueBand]
+	alpha, hasAlpha := bands[CI_AlphaBand]
+
+	switch {
+	case first.ColorInterp() == CI_PaletteIndex && paletteSize(first.ColorTable()) > 0:
+		img := image.NewPaletted(bounds, toPalette(first.ColorTable()))
+		if err := read(first, img.Pix); err != nil {
+			return nil, err
+		}
+		// Pad the palette so that At never indexes past its end
+		for _, index := range img.Pix {
+			for int(index) >= len(img.Palette) {
+				img.Palette = append(img.Palette, color.NRGBA{})
+			}
+		}
+		return img, nil
+
+	case hasRed && hasGreen && hasBlue:
+	case count >= 3:
+		red, green, blue = first, dataset.RasterBand(2), dataset.RasterBand(3)
+		if !hasAlpha && count >= 4 {
+			alpha, hasAlpha = dataset.RasterBand(4), true
+		}
+	case count == 2 && hasAlpha:
+		gray := first
+		if alpha.cval == first.cval {
+			gray = dataset.RasterBand(2)
+		}
+		red, green, blue = gray, gray, gray
+
+	case first.RasterDataType() == Byte:
+		img := image.NewGray(bounds)
+		if err := read(first, img.Pix); err != nil {
+			return nil, err
+		}
+		return img, nil
+
+	default:
+		img := image.NewGray16(bounds)
+		values := make([]uint16, window.XSize*window.YSize)
+		err := ReadRegion(
+			first,
+			window.XOff, window.YOff, window.XSize, window.YSize,
+			values, window.XSize, window.YSize,
+		)
+		if err != nil {
+			return nil, err
+		}
+		for i, v := range values {
+			binary.BigEndian.PutUint16(img.Pix[2*i:], v)
+		}
+		return img, nil
+	}
+
+	img := image.NewRGBA(bounds)
+	n := window.XSize * window.YSize
+	channels := []RasterBand{red, green, blue}
+	if hasAlpha {
+		channels = append(channels, alpha)
+	}
+	buf := make([]uint8, n)
+	for c, band := range channels {
+		if err := read(band, buf); err != nil {
+			return nil, err
+		}
+		for i, v := range buf {
+			img.Pix[4*i+c] = v
+		}
+	}
+	for i := 0; i < n; i++ {
+		pix := img.Pix[4*i : 4*i+4]
+		if !hasAlpha {
+			pix[3] = 0xff
+			continue
+		}
+		// image.RGBA holds alpha premultiplied colors
+		a := uint32(pix[3])
+		for c := 0; c < 3; c++ {
+			pix[c] = uint8(uint32(pix[c]) * a / 0xff)
+		}
+	}
+	return img, nil
+}
+
+// paletteSize returns the number of entries of a color table that fits in
+// an image.Paletted, or 0
+func paletteSize(ct ColorTable) int {
+	if ct.IsNil() || ct.PaletteInterpretation() != PI_RGB {
+		return 0
+	}
+	if count := ct.EntryCount(); count <= 256 {
+		return count
+	}
+	return 0
+}
+
+// toPalette converts an RGB color table to a Go palette
+func toPalette(ct ColorTable) color.Palette {
+	palette := make(color.Palette, paletteSize(ct))
+	for i := range palette {
+		entry := ct.Entry(i).cval
+		palette[i] = color.NRGBA{
+			R: uint8(entry.c1),
+			G: uint8(entry.c2),
+			B: uint8(entry.c3),
+			A: uint8(entry.c4),
+		}
+	}
+	return palette
+}
+
+// NewDatasetFromImage creates a dataset holding a copy of img with the named
+// driver, which must be able to create datasets without a file name, such
+// as MEM. Use Driver.CreateCopy to write the result to a file. Pixel (0, 0)
+// of the dataset is the top left corner of the image bounds.
+//
+// An *image.Gray gives a Byte band, an *image.Gray16 a UInt16 band and an
+// *image.Paletted a Byte band with a color table. Other images give red,
+// green and blue Byte bands, plus an alpha band unless the image is opaque.
+func NewDatasetFromImage(img image.Image, driver string) (Dataset, error) {
+	drv, err := GetDriverByName(driver)
+	if err != nil {
+		return Dataset{}, err
+	}
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width == 0 || height == 0 {
+		return Dataset{}, fmt.Errorf("image is empty")
+	}
+	create := func(bands int, dataType DataType) (Dataset, error) {
+		return drv.Create("", width, height, bands, dataType, nil)
+	}
+	write := func(band RasterBand, buf []uint8) error {
+		return WriteRegion(band, 0, 0, width, height, buf, width, height)
+	}
+
+	switch src := img.(type) {
+	case *image.Gray:
+		ds, err := create(1, Byte)
+		if err != nil {
+			return Dataset{}, err
+		}
+		band := ds.RasterBand(1)
+		if err := band.SetColorInterp(CI_GrayIndex); err != nil {
+			ds.Close()
+			return Dataset{}, err
+		}
+		if err := write(band, packRows(src.Pix, src.Stride, width, height)); err != nil {
+			ds.Close()
+			return Dataset{}, err
+		}
+		return ds, nil
+
+	case *image.Gray16:
+		ds, err := create(1, UInt16)
+		if err != nil {
+			return Dataset{}, err
+		}
+		band := ds.RasterBand(1)
+		if err := band.SetColorInterp(CI_GrayIndex); err != nil {
+			ds.Close()
+			return Dataset{}, err
+		}
+		pix := packRows(src.Pix, src.Stride, 2*width, height)
+		values := make([]uint16, width*height)
+		for i := range values {
+			values[i] = binary.BigEndian.Uint16(pix[2*i:])
+		}
+		err = WriteRegion(band, 0, 0, width, height, values, width, height)
+		if err != nil {
+			ds.Close()
+			return Dataset{}, err
+		}
+		return ds, nil
+
+	case *image.Paletted:
+		ds, err := create(1, Byte)
+		if err != nil {
+			return Dataset{}, err
+		}
+		band := ds.RasterBand(1)
+		if err := setPalette(band, src.Palette); err != nil {
+			ds.Close()
+			return Dataset{}, err
+		}
+		if err := write(band, packRows(src.Pix, src.Stride, width, height)); err != nil {
+			ds.Close()
+			return Dataset{}, err
+		}
+		return ds, nil
+	}
+
+	opaque := false
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		opaque = o.Opaque()
+	}
+	bands := 4
+	if opaque {
+		bands = 3
+	}
+	ds, err := create(bands, Byte)
+	if err != nil {
+		return Dataset{}, err
+	}
+	channels := make([][]uint8, bands)
+	for c := range channels {
+		channels[c] = make([]uint8, width*height)
+	}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.NRGBAModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.NRGBA)
+			i := y*width + x
+			channels[0][i], channels[1][i], channels[2][i] = c.R, c.G, c.B
+			if !opaque {
+				channels[3][i] = c.A
+			}
+		}
+	}
+	interps := []ColorInterp{CI_RedBand, CI_GreenBand, CI_BlueBand, CI_AlphaBand}
+	for c, buf := range channels {
+		band := ds.RasterBand(c + 1)
+		if err := band.SetColorInterp(interps[c]); err != nil {
+			ds.Close()
+			return Dataset{}, err
+		}
+		if err := write(band, buf); err != nil {
+			ds.Close()
+			return Dataset{}, err
+		}
+	}
+	return ds, nil
+}
+
+// packRows returns the rows of an image's pixel buffer without the padding
+// between them
+func packRows(pix []uint8, stride, rowBytes, height int) []uint8 {
+	if stride == rowBytes {
+		return pix[:rowBytes*height]
+	}
+	packed := make([]uint8, 0, rowBytes*height)
+	for y := 0; y < height; y++ {
+		packed = append(packed, pix[y*stride:y*stride+rowBytes]...)
+	}
+	return packed
+}
+
+// setPalette gives a band the color table of a Go palette
+func setPalette(band RasterBand, palette color.Palette) error {
+	ct := CreateColorTable(PI_RGB)
+	defer ct.Close()
+	for i, c := range palette {
+		nrgba := color.NRGBAModel.Convert(c).(color.NRGBA)
+		entry := C.GDALColorEntry{
+			c1: C.short(nrgba.R),
+			c2: C.short(nrgba.G),
+			c3: C.short(nrgba.B),
+			c4: C.short(nrgba.A),
+		}
+		ct.SetEntry(i, ColorEntry{&entry})
+	}
+	if err := band.SetColorInterp(CI_PaletteIndex); err != nil {
+		return err
+	}
+	return band.SetColorTable(ct)
+}
@@ -2,17 +2,11 @@ package gdal
 
 /*
 #include "go_gdal.h"
-#include "gdal_version.h"
-
-#cgo darwin pkg-config: gdal
-#cgo linux LDFLAGS: -L/usr/local/lib -lgdal
-#cgo linux CFLAGS: -I/usr/local/include
-#cgo windows LDFLAGS: -LC:/Python27/Lib/site-packages/osgeo/lib -lgdal_i
-#cgo windows CFLAGS: -IC:/Python27/Lib/site-packages/osgeo/include/gdal
 */
 import "C"
 import (
 	"reflect"
+	"runtime"
 	"time"
 	"unsafe"
 )
@@ -184,45 +178,69 @@ func BoolToCInt(in bool) (out C.int) {
 
 type Geometry struct {
 	cval C.OGRGeometryH
+	*handle
+}
+
+// Return true if this geometry wraps a NULL handle or has been destroyed
+func (geom Geometry) IsNil() bool {
+	return geom.cval == nil || geom.isClosed()
+}
+
+func newGeometry(h C.OGRGeometryH) Geometry {
+	return Geometry{h, ownHandle("Geometry", h, func(h C.OGRGeometryH) error {
+		C.OGR_G_DestroyGeometry(h)
+		return nil
+	})}
 }
 
 //Create a geometry object from its well known binary representation
 func CreateFromWKB(wkb []uint8, srs SpatialReference, bytes int) (Geometry, error) {
+	defer runtime.KeepAlive(srs.handle)
+	defer pinErrorState()()
 	cString := (*C.uchar)(unsafe.Pointer(&wkb[0]))
-	var newGeom Geometry
-	return newGeom, C.OGR_G_CreateFromWkb(
-		cString, srs.cval, &newGeom.cval, C.int(bytes),
+	var newGeom C.OGRGeometryH
+	err := C.OGR_G_CreateFromWkb(
+		cString, srs.cval, &newGeom, C.int(bytes),
 	).Err()
+	return newGeometry(newGeom), err
 }
 
 //Create a geometry object from its well known text representation
 func CreateFromWKT(wkt string, srs SpatialReference) (Geometry, error) {
+	defer runtime.KeepAlive(srs.handle)
+	defer pinErrorState()()
 	cString := C.CString(wkt)
 	defer C.free(unsafe.Pointer(cString))
-	var newGeom Geometry
-	return newGeom, C.OGR_G_CreateFromWkt(
-		&cString, srs.cval, &newGeom.cval,
+	var newGeom C.OGRGeometryH
+	err := C.OGR_G_CreateFromWkt(
+		&cString, srs.cval, &newGeom,
 	).Err()
+	return newGeometry(newGeom), err
 }
 
 //Create a geometry object from its GeoJSON representation
 func CreateFromJson(_json string) Geometry {
 	cString := C.CString(_json)
 	defer C.free(unsafe.Pointer(cString))
-	var newGeom Geometry
-	newGeom.cval = C.OGR_G_CreateGeometryFromJson(cString)
-	return newGeom
+	newGeom := C.OGR_G_CreateGeometryFromJson(cString)
+	return newGeometry(newGeom)
 }
 
 // Destroy geometry object
 func (geometry Geometry) Destroy() {
-	C.OGR_G_DestroyGeometry(geometry.cval)
+	geometry.close()
+}
+
+// Destroy geometry object; a no-op for geometries owned by a feature or
+// another geometry
+func (geometry Geometry) Close() error {
+	return geometry.close()
 }
 
 // Create an empty geometry of the desired type
 func Create(geomType GeometryType) Geometry {
 	geom := C.OGR_G_CreateGeometry(C.OGRwkbGeometryType(geomType))
-	return Geometry{geom}
+	return newGeometry(geom)
 }
 
 // Stroke arc to linestring
@@ -245,58 +263,71 @@ func ApproximateArcAngles(
 		C.double(startAngle),
 		C.double(endAngle),
 		C.double(stepSizeDegrees))
-	return Geometry{geom}
+	return newGeometry(geom)
 }
 
 // Convert to polygon
 func (geom Geometry) ForceToPolygon() Geometry {
+	defer runtime.KeepAlive(geom.handle)
 	newGeom := C.OGR_G_ForceToPolygon(geom.cval)
-	return Geometry{newGeom}
+	geom.consume()
+	return newGeometry(newGeom)
 }
 
 // Convert to multipolygon
 func (geom Geometry) ForceToMultiPolygon() Geometry {
+	defer runtime.KeepAlive(geom.handle)
 	newGeom := C.OGR_G_ForceToMultiPolygon(geom.cval)
-	return Geometry{newGeom}
+	geom.consume()
+	return newGeometry(newGeom)
 }
 
 // Convert to multipoint
 func (geom Geometry) ForceToMultiPoint() Geometry {
+	defer runtime.KeepAlive(geom.handle)
 	newGeom := C.OGR_G_ForceToMultiPoint(geom.cval)
-	return Geometry{newGeom}
+	geom.consume()
+	return newGeometry(newGeom)
 }
 
 // Convert to multilinestring
 func (geom Geometry) ForceToMultiLineString() Geometry {
+	defer runtime.KeepAlive(geom.handle)
 	newGeom := C.OGR_G_ForceToMultiLineString(geom.cval)
-	return Geometry{newGeom}
+	geom.consume()
+	return newGeometry(newGeom)
 }
 
 // Get the dimension of this geometry
 func (geom Geometry) Dimension() int {
+	defer runtime.KeepAlive(geom.handle)
 	dim := C.OGR_G_GetDimension(geom.cval)
 	return int(dim)
 }
 
 // Get the dimension of the coordinates in this geometry
 func (geom Geometry) CoordinateDimension() int {
+	defer runtime.KeepAlive(geom.handle)
 	dim := C.OGR_G_GetCoordinateDimension(geom.cval)
 	return int(dim)
 }
 
 // Set the dimension of the coordinates in this geometry
 func (geom Geometry) SetCoordinateDimension(dim int) {
+	defer runtime.KeepAlive(geom.handle)
 	C.OGR_G_SetCoordinateDimension(geom.cval, C.int(dim))
 }
 
 // Create a copy of this geometry
 func (geom Geometry) Clone() Geometry {
+	defer runtime.KeepAlive(geom.handle)
 	newGeom := C.OGR_G_Clone(geom.cval)
-	return Geometry{newGeom}
+	return newGeometry(newGeom)
 }
 
 // Compute and return the bounding envelope for this geometry
 func (geom Geometry) Envelope() Envelope {
+	defer runtime.KeepAlive(geom.handle)
 	var env Envelope
 	C.OGR_G_GetEnvelope(geom.cval, &env.cval)
 	return env
@@ -306,12 +337,16 @@ func (geom Geometry) Envelope() Envelope {
 
 // Assign a geometry from well known binary data
 func (geom Geometry) FromWKB(wkb []uint8, bytes int) error {
+	defer runtime.KeepAlive(geom.handle)
+	defer pinErrorState()()
 	cString := (*C.uchar)(unsafe.Pointer(&wkb[0]))
 	return C.OGR_G_ImportFromWkb(geom.cval, cString, C.int(bytes)).Err()
 }
 
 // Convert a geometry to well known binary data
 func (geom Geometry) ToWKB() ([]uint8, error) {
+	defer runtime.KeepAlive(geom.handle)
+	defer pinErrorState()()
 	b := make([]uint8, geom.WKBSize())
 	cString := (*C.uchar)(unsafe.Pointer(&b[0]))
 	err := C.OGR_G_ExportToWkb(geom.cval, C.OGRwkbByteOrder(C.wkbNDR), cString).Err()
@@ -320,12 +355,15 @@ func (geom Geometry) ToWKB() ([]uint8, error) {
 
 // Returns size of related binary representation
 func (geom Geometry) WKBSize() int {
+	defer runtime.KeepAlive(geom.handle)
 	size := C.OGR_G_WkbSize(geom.cval)
 	return int(size)
 }
 
 // Assign geometry object from its well known text representation
 func (geom Geometry) FromWKT(wkt string) error {
+	defer runtime.KeepAlive(geom.handle)
+	defer pinErrorState()()
 	cString := C.CString(wkt)
 	defer C.free(unsafe.Pointer(cString))
 	return C.OGR_G_ImportFromWkt(geom.cval, &cString).Err()
@@ -333,6 +371,8 @@ func (geom Geometry) FromWKT(wkt string) error {
 
 // Fetch geometry as WKT
 func (geom Geometry) ToWKT() (string, error) {
+	defer runtime.KeepAlive(geom.handle)
+	defer pinErrorState()()
 	var p *C.char
 	err := C.OGR_G_ExportToWkt(geom.cval, &p).Err()
 	wkt := C.GoString(p)
@@ -341,12 +381,14 @@ func (geom Geometry) ToWKT() (string, error) {
 
 // Fetch geometry type
 func (geom Geometry) Type() GeometryType {
+	defer runtime.KeepAlive(geom.handle)
 	gt := C.OGR_G_GetGeometryType(geom.cval)
 	return GeometryType(gt)
 }
 
 // Fetch geometry name
 func (geom Geometry) Name() string {
+	defer runtime.KeepAlive(geom.handle)
 	name := C.OGR_G_GetGeometryName(geom.cval)
 	return C.GoString(name)
 }
@@ -355,11 +397,13 @@ func (geom Geometry) Name() string {
 
 // Convert geometry to strictly 2D
 func (geom Geometry) FlattenTo2D() {
+	defer runtime.KeepAlive(geom.handle)
 	C.OGR_G_FlattenTo2D(geom.cval)
 }
 
 // Force rings to be closed
 func (geom Geometry) CloseRings() {
+	defer runtime.KeepAlive(geom.handle)
 	C.OGR_G_CloseRings(geom.cval)
 }
 
@@ -368,17 +412,19 @@ func CreateFromGML(gml string) Geometry {
 	cString := C.CString(gml)
 	defer C.free(unsafe.Pointer(cString))
 	geom := C.OGR_G_CreateFromGML(cString)
-	return Geometry{geom}
+	return newGeometry(geom)
 }
 
 // Convert a geometry to GML format
 func (geom Geometry) ToGML() string {
+	defer runtime.KeepAlive(geom.handle)
 	val := C.OGR_G_ExportToGML(geom.cval)
 	return C.GoString(val)
 }
 
 // Convert a geometry to GML format with options
 func (geom Geometry) ToGML_Ex(options []string) string {
+	defer runtime.KeepAlive(geom.handle)
 	length := len(options)
 	opts := make([]*C.char, length+1)
 	for i := 0; i < length; i++ {
@@ -393,18 +439,21 @@ func (geom Geometry) ToGML_Ex(options []string) string {
 
 // Convert a geometry to KML format
 func (geom Geometry) ToKML() string {
+	defer runtime.KeepAlive(geom.handle)
 	val := C.OGR_G_ExportToKML(geom.cval, nil)
 	return C.GoString(val)
 }
 
 // Convert a geometry to JSON format
 func (geom Geometry) ToJSON() string {
+	defer runtime.KeepAlive(geom.handle)
 	val := C.OGR_G_ExportToJson(geom.cval)
 	return C.GoString(val)
 }
 
 // Convert a geometry to JSON format with options
 func (geom Geometry) ToJSON_ex(options []string) string {
+	defer runtime.KeepAlive(geom.handle)
 	length := len(options)
 	opts := make([]*C.char, length+1)
 	for i := 0; i < length; i++ {
@@ -419,203 +468,270 @@ func (geom Geometry) ToJSON_ex(options []string) string {
 
 // Fetch the spatial reference associated with this geometry
 func (geom Geometry) SpatialReference() SpatialReference {
+	defer runtime.KeepAlive(geom.handle)
 	spatialRef := C.OGR_G_GetSpatialReference(geom.cval)
-	return SpatialReference{spatialRef}
+	return SpatialReference{spatialRef, borrowHandle(geom.handle)}
 }
 
 // Assign a spatial reference to this geometry
 func (geom Geometry) SetSpatialReference(spatialRef SpatialReference) {
+	defer runtime.KeepAlive(geom.handle)
+	defer runtime.KeepAlive(spatialRef.handle)
 	C.OGR_G_AssignSpatialReference(geom.cval, spatialRef.cval)
 }
 
 // Apply coordinate transformation to geometry
 func (geom Geometry) Transform(ct CoordinateTransform) error {
+	defer runtime.KeepAlive(geom.handle)
+	defer runtime.KeepAlive(ct.handle)
+	defer pinErrorState()()
 	return C.OGR_G_Transform(geom.cval, ct.cval).Err()
 }
 
 // Transform geometry to new spatial reference system
 func (geom Geometry) TransformTo(sr SpatialReference) error {
+	defer runtime.KeepAlive(geom.handle)
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OGR_G_TransformTo(geom.cval, sr.cval).Err()
 }
 
 // Simplify the geometry
 func (geom Geometry) Simplify(tolerance float64) Geometry {
+	defer runtime.KeepAlive(geom.handle)
 	newGeom := C.OGR_G_Simplify(geom.cval, C.double(tolerance))
-	return Geometry{newGeom}
+	return newGeometry(newGeom)
 }
 
 // Simplify the geometry while preserving topology
 func (geom Geometry) SimplifyPreservingTopology(tolerance float64) Geometry {
+	defer runtime.KeepAlive(geom.handle)
 	newGeom := C.OGR_G_SimplifyPreserveTopology(geom.cval, C.double(tolerance))
-	return Geometry{newGeom}
+	return newGeometry(newGeom)
 }
 
 // Modify the geometry such that it has no line segment longer than the given distance
 func (geom Geometry) Segmentize(distance float64) {
+	defer runtime.KeepAlive(geom.handle)
 	C.OGR_G_Segmentize(geom.cval, C.double(distance))
 }
 
 // Return true if these features intersect
 func (geom Geometry) Intersects(other Geometry) bool {
+	defer runtime.KeepAlive(geom.handle)
+	defer runtime.KeepAlive(other.handle)
 	val := C.OGR_G_Intersects(geom.cval, other.cval)
 	return val != 0
 }
 
 // Return true if these features are equal
 func (geom Geometry) Equals(other Geometry) bool {
+	defer runtime.KeepAlive(geom.handle)
+	defer runtime.KeepAlive(other.handle)
 	val := C.OGR_G_Equals(geom.cval, other.cval)
 	return val != 0
 }
 
 // Return true if the features are disjoint
 func (geom Geometry) Disjoint(other Geometry) bool {
+	defer runtime.KeepAlive(geom.handle)
+	defer runtime.KeepAlive(other.handle)
 	val := C.OGR_G_Disjoint(geom.cval, other.cval)
 	return val != 0
 }
 
 // Return true if this feature touches the other
 func (geom Geometry) Touches(other Geometry) bool {
+	defer runtime.KeepAlive(geom.handle)
+	defer runtime.KeepAlive(other.handle)
 	val := C.OGR_G_Touches(geom.cval, other.cval)
 	return val != 0
 }
 
 // Return true if this feature crosses the other
 func (geom Geometry) Crosses(other Geometry) bool {
+	defer runtime.KeepAlive(geom.handle)
+	defer runtime.KeepAlive(other.handle)
 	val := C.OGR_G_Crosses(geom.cval, other.cval)
 	return val != 0
 }
 
 // Return true if this geometry is within the other
 func (geom Geometry) Within(other Geometry) bool {
+	defer runtime.KeepAlive(geom.handle)
+	defer runtime.KeepAlive(other.handle)
 	val := C.OGR_G_Within(geom.cval, other.cval)
 	return val != 0
 }
 
 // Return true if this geometry contains the other
 func (geom Geometry) Contains(other Geometry) bool {
+	defer runtime.KeepAlive(geom.handle)
+	defer runtime.KeepAlive(other.handle)
 	val := C.OGR_G_Contains(geom.cval, other.cval)
 	return val != 0
 }
 
 // Return true if this geometry overlaps the other
 func (geom Geometry) Overlaps(other Geometry) bool {
+	defer runtime.KeepAlive(geom.handle)
+	defer runtime.KeepAlive(other.handle)
 	val := C.OGR_G_Overlaps(geom.cval, other.cval)
 	return val != 0
 }
 
 // Compute boundary for the geometry
 func (geom Geometry) Boundary() Geometry {
+	defer runtime.KeepAlive(geom.handle)
 	newGeom := C.OGR_G_Boundary(geom.cval)
-	return Geometry{newGeom}
+	return newGeometry(newGeom)
 }
 
 // Compute convex hull for the geometry
 func (geom Geometry) ConvexHull() Geometry {
+	defer runtime.KeepAlive(geom.handle)
 	newGeom := C.OGR_G_ConvexHull(geom.cval)
-	return Geometry{newGeom}
+	return newGeometry(newGeom)
 }
 
 // Compute buffer of the geometry
 func (geom Geometry) Buffer(distance float64, segments int) Geometry {
+	defer runtime.KeepAlive(geom.handle)
 	newGeom := C.OGR_G_Buffer(geom.cval, C.double(distance), C.int(segments))
-	return Geometry{newGeom}
+	return newGeometry(newGeom)
 }
 
 // Compute intersection of this geometry with the other
 func (geom Geometry) Intersection(other Geometry) Geometry {
+	defer runtime.KeepAlive(geom.handle)
+	defer runtime.KeepAlive(other.handle)
 	newGeom := C.OGR_G_Intersection(geom.cval, other.cval)
-	return Geometry{newGeom}
+	return newGeometry(newGeom)
 }
 
 // Compute union of this geometry with the other
 func (geom Geometry) Union(other Geometry) Geometry {
+	defer runtime.KeepAlive(geom.handle)
+	defer runtime.KeepAlive(other.handle)
 	newGeom := C.OGR_G_Union(geom.cval, other.cval)
-	return Geometry{newGeom}
+	return newGeometry(newGeom)
 }
 
 // Unimplemented: UnionCascaded
 
-// Unimplemented: PointOn Surface (until 2.0)
-// Return a point guaranteed to lie on the surface
-// func (geom Geometry) PointOnSurface() Geometry {
-//	newGeom := C.OGR_G_PointOnSurface(geom.cval)
-//	return Geometry{newGeom}
-// }
+// Return a point guaranteed to lie on the surface. Requires GDAL 2.0.
+func (geom Geometry) PointOnSurface() (Geometry, error) {
+	defer runtime.KeepAlive(geom.handle)
+	defer pinErrorState()()
+	newGeom := C.goGeometryPointOnSurface(geom.cval)
+	if newGeom == nil {
+		return Geometry{}, lastError(CE_Failure)
+	}
+	return newGeometry(newGeom), nil
+}
+
+// Return a valid version of an invalid geometry, keeping all its vertices.
+// Requires GDAL 3.0.
+func (geom Geometry) MakeValid() (Geometry, error) {
+	defer runtime.KeepAlive(geom.handle)
+	defer pinErrorState()()
+	newGeom := C.goGeometryMakeValid(geom.cval)
+	if newGeom == nil {
+		return Geometry{}, lastError(CE_Failure)
+	}
+	return newGeometry(newGeom), nil
+}
 
 // Compute difference between this geometry and the other
 func (geom Geometry) Difference(other Geometry) Geometry {
+	defer runtime.KeepAlive(geom.handle)
+	defer runtime.KeepAlive(other.handle)
 	newGeom := C.OGR_G_Difference(geom.cval, other.cval)
-	return Geometry{newGeom}
+	return newGeometry(newGeom)
 }
 
 // Compute symmetric difference between this geometry and the other
 func (geom Geometry) SymmetricDifference(other Geometry) Geometry {
+	defer runtime.KeepAlive(geom.handle)
+	defer runtime.KeepAlive(other.handle)
 	newGeom := C.OGR_G_SymDifference(geom.cval, other.cval)
-	return Geometry{newGeom}
+	return newGeometry(newGeom)
 }
 
 // Compute distance between thie geometry and the other
 func (geom Geometry) Distance(other Geometry) float64 {
+	defer runtime.KeepAlive(geom.handle)
+	defer runtime.KeepAlive(other.handle)
 	dist := C.OGR_G_Distance(geom.cval, other.cval)
 	return float64(dist)
 }
 
 // Compute length of geometry
 func (geom Geometry) Length() float64 {
+	defer runtime.KeepAlive(geom.handle)
 	length := C.OGR_G_Length(geom.cval)
 	return float64(length)
 }
 
 // Compute area of geometry
 func (geom Geometry) Area() float64 {
+	defer runtime.KeepAlive(geom.handle)
 	area := C.OGR_G_Area(geom.cval)
 	return float64(area)
 }
 
 // Compute centroid of geometry
 func (geom Geometry) Centroid() Geometry {
-	var centroid Geometry
+	defer runtime.KeepAlive(geom.handle)
+	centroid := Create(GT_Point)
 	C.OGR_G_Centroid(geom.cval, centroid.cval)
 	return centroid
 }
 
 // Clear the geometry to its uninitialized state
 func (geom Geometry) Empty() {
+	defer runtime.KeepAlive(geom.handle)
 	C.OGR_G_Empty(geom.cval)
 }
 
 // Test if the geometry is empty
 func (geom Geometry) IsEmpty() bool {
+	defer runtime.KeepAlive(geom.handle)
 	val := C.OGR_G_IsEmpty(geom.cval)
 	return val != 0
 }
 
 // Test if the geometry is valid
 func (geom Geometry) IsValid() bool {
+	defer runtime.KeepAlive(geom.handle)
 	val := C.OGR_G_IsValid(geom.cval)
 	return val != 0
 }
 
 // Test if the geometry is simple
 func (geom Geometry) IsSimple() bool {
+	defer runtime.KeepAlive(geom.handle)
 	val := C.OGR_G_IsSimple(geom.cval)
 	return val != 0
 }
 
 // Test if the geometry is a ring
 func (geom Geometry) IsRing() bool {
+	defer runtime.KeepAlive(geom.handle)
 	val := C.OGR_G_IsRing(geom.cval)
 	return val != 0
 }
 
 // Polygonize a set of sparse edges
 func (geom Geometry) Polygonize() Geometry {
+	defer runtime.KeepAlive(geom.handle)
 	newGeom := C.OGR_G_Polygonize(geom.cval)
-	return Geometry{newGeom}
+	return newGeometry(newGeom)
 }
 
 // Fetch number of points in the geometry
 func (geom Geometry) PointCount() int {
+	defer runtime.KeepAlive(geom.handle)
 	count := C.OGR_G_GetPointCount(geom.cval)
 	return int(count)
 }
@@ -624,24 +740,28 @@ func (geom Geometry) PointCount() int {
 
 // Fetch the X coordinate of a point in the geometry
 func (geom Geometry) X(index int) float64 {
+	defer runtime.KeepAlive(geom.handle)
 	x := C.OGR_G_GetX(geom.cval, C.int(index))
 	return float64(x)
 }
 
 // Fetch the Y coordinate of a point in the geometry
 func (geom Geometry) Y(index int) float64 {
+	defer runtime.KeepAlive(geom.handle)
 	y := C.OGR_G_GetY(geom.cval, C.int(index))
 	return float64(y)
 }
 
 // Fetch the Z coordinate of a point in the geometry
 func (geom Geometry) Z(index int) float64 {
+	defer runtime.KeepAlive(geom.handle)
 	z := C.OGR_G_GetZ(geom.cval, C.int(index))
 	return float64(z)
 }
 
 // Fetch the coordinates of a point in the geometry
 func (geom Geometry) Point(index int) (x, y, z float64) {
+	defer runtime.KeepAlive(geom.handle)
 	C.OGR_G_GetPoint(
 		geom.cval,
 		C.int(index),
@@ -653,6 +773,7 @@ func (geom Geometry) Point(index int) (x, y, z float64) {
 
 // Set the coordinates of a point in the geometry
 func (geom Geometry) SetPoint(index int, x, y, z float64) {
+	defer runtime.KeepAlive(geom.handle)
 	C.OGR_G_SetPoint(
 		geom.cval,
 		C.int(index),
@@ -663,48 +784,64 @@ func (geom Geometry) SetPoint(index int, x, y, z float64) {
 
 // Set the coordinates of a point in the geometry, ignoring the 3rd dimension
 func (geom Geometry) SetPoint2D(index int, x, y float64) {
+	defer runtime.KeepAlive(geom.handle)
 	C.OGR_G_SetPoint_2D(geom.cval, C.int(index), C.double(x), C.double(y))
 }
 
 // Add a new point to the geometry (line string or polygon only)
 func (geom Geometry) AddPoint(x, y, z float64) {
+	defer runtime.KeepAlive(geom.handle)
 	C.OGR_G_AddPoint(geom.cval, C.double(x), C.double(y), C.double(z))
 }
 
 // Add a new point to the geometry (line string or polygon only), ignoring the 3rd dimension
 func (geom Geometry) AddPoint2D(x, y float64) {
+	defer runtime.KeepAlive(geom.handle)
 	C.OGR_G_AddPoint_2D(geom.cval, C.double(x), C.double(y))
 }
 
 // Fetch the number of elements in the geometry, or number of geometries in the container
 func (geom Geometry) GeometryCount() int {
+	defer runtime.KeepAlive(geom.handle)
 	count := C.OGR_G_GetGeometryCount(geom.cval)
 	return int(count)
 }
 
 // Fetch geometry from a geometry container
 func (geom Geometry) Geometry(index int) Geometry {
+	defer runtime.KeepAlive(geom.handle)
 	newGeom := C.OGR_G_GetGeometryRef(geom.cval, C.int(index))
-	return Geometry{newGeom}
+	return Geometry{newGeom, borrowHandle(geom.handle)}
 }
 
 // Add a geometry to a geometry container
 func (geom Geometry) AddGeometry(other Geometry) error {
+	defer runtime.KeepAlive(geom.handle)
+	defer runtime.KeepAlive(other.handle)
+	defer pinErrorState()()
 	return C.OGR_G_AddGeometry(geom.cval, other.cval).Err()
 }
 
 // Add a geometry to a geometry container and assign ownership to that container
 func (geom Geometry) AddGeometryDirectly(other Geometry) error {
+	defer runtime.KeepAlive(geom.handle)
+	defer runtime.KeepAlive(other.handle)
+	defer pinErrorState()()
+	other.disown(geom.handle)
 	return C.OGR_G_AddGeometryDirectly(geom.cval, other.cval).Err()
 }
 
 // Remove a geometry from the geometry container
 func (geom Geometry) RemoveGeometry(index int, delete bool) error {
+	defer runtime.KeepAlive(geom.handle)
+	defer pinErrorState()()
 	return C.OGR_G_RemoveGeometry(geom.cval, C.int(index), BoolToCInt(delete)).Err()
 }
 
 // Build a polygon / ring from a set of lines
 func (geom Geometry) BuildPolygonFromEdges(autoClose bool, tolerance float64) (Geometry, error) {
+	defer runtime.KeepAlive(geom.handle)
+	defer pinErrorState()()
 	var cErr C.OGRErr
 	newGeom := C.OGRBuildPolygonFromEdges(
 		geom.cval,
@@ -713,7 +850,7 @@ func (geom Geometry) BuildPolygonFromEdges(autoClose bool, tolerance float64) (G
 		C.double(tolerance),
 		&cErr,
 	)
-	return Geometry{newGeom}, cErr.Err()
+	return newGeometry(newGeom), cErr.Err()
 }
 
 /* -------------------------------------------------------------------- */
@@ -748,10 +885,20 @@ type FieldDefinition struct {
 	cval C.OGRFieldDefnH
 }
 
+// Return true if this field definition wraps a NULL handle
+func (fd FieldDefinition) IsNil() bool {
+	return fd.cval == nil
+}
+
 type Field struct {
 	cval *C.OGRField
 }
 
+// Return true if this field wraps a NULL pointer
+func (field Field) IsNil() bool {
+	return field.cval == nil
+}
+
 // Create a new field definition
 func CreateFieldDefinition(name string, fieldType FieldType) FieldDefinition {
 	cName := C.CString(name)
@@ -867,6 +1014,11 @@ type FeatureDefinition struct {
 	cval C.OGRFeatureDefnH
 }
 
+// Return true if this feature definition wraps a NULL handle
+func (fd FeatureDefinition) IsNil() bool {
+	return fd.cval == nil
+}
+
 // Create a new feature definition object
 func CreateFeatureDefinition(name string) FeatureDefinition {
 	cName := C.CString(name)
@@ -918,6 +1070,7 @@ func (fd FeatureDefinition) AddFieldDefinition(fieldDefn FieldDefinition) {
 
 // Delete a field definition from this feature definition
 func (fd FeatureDefinition) DeleteFieldDefinition(index int) error {
+	defer pinErrorState()()
 	return C.OGR_FD_DeleteFieldDefn(fd.cval, C.int(index)).Err()
 }
 
@@ -978,73 +1131,107 @@ func (fd FeatureDefinition) ReferenceCount() int {
 
 type Feature struct {
 	cval C.OGRFeatureH
+	*handle
+}
+
+// Return true if this feature wraps a NULL handle or has been destroyed
+func (feature Feature) IsNil() bool {
+	return feature.cval == nil || feature.isClosed()
+}
+
+func newFeature(h C.OGRFeatureH) Feature {
+	return Feature{h, ownHandle("Feature", h, func(h C.OGRFeatureH) error {
+		C.OGR_F_Destroy(h)
+		return nil
+	})}
 }
 
 // Create a feature from this feature definition
 func (fd FeatureDefinition) Create() Feature {
 	feature := C.OGR_F_Create(fd.cval)
-	return Feature{feature}
+	return newFeature(feature)
 }
 
 // Destroy this feature
 func (feature Feature) Destroy() {
-	C.OGR_F_Destroy(feature.cval)
+	feature.close()
+}
+
+// Destroy this feature, invalidating geometries borrowed from it
+func (feature Feature) Close() error {
+	return feature.close()
 }
 
 // Fetch feature definition
 func (feature Feature) Definition() FeatureDefinition {
+	defer runtime.KeepAlive(feature.handle)
 	fd := C.OGR_F_GetDefnRef(feature.cval)
 	return FeatureDefinition{fd}
 }
 
 // Set feature geometry
 func (feature Feature) SetGeometry(geom Geometry) error {
+	defer runtime.KeepAlive(feature.handle)
+	defer runtime.KeepAlive(geom.handle)
+	defer pinErrorState()()
 	return C.OGR_F_SetGeometry(feature.cval, geom.cval).Err()
 }
 
 // Set feature geometry, passing ownership to the feature
 func (feature Feature) SetGeometryDirectly(geom Geometry) error {
+	defer runtime.KeepAlive(feature.handle)
+	defer runtime.KeepAlive(geom.handle)
+	defer pinErrorState()()
+	geom.disown(feature.handle)
 	return C.OGR_F_SetGeometryDirectly(feature.cval, geom.cval).Err()
 }
 
 // Fetch geometry of this feature
 func (feature Feature) Geometry() Geometry {
+	defer runtime.KeepAlive(feature.handle)
 	geom := C.OGR_F_GetGeometryRef(feature.cval)
-	return Geometry{geom}
+	return Geometry{geom, borrowHandle(feature.handle)}
 }
 
 // Fetch geometry of this feature and assume ownership
 func (feature Feature) StealGeometry() Geometry {
+	defer runtime.KeepAlive(feature.handle)
 	geom := C.OGR_F_StealGeometry(feature.cval)
-	return Geometry{geom}
+	return newGeometry(geom)
 }
 
 // Duplicate feature
 func (feature Feature) Clone() Feature {
-	newFeature := C.OGR_F_Clone(feature.cval)
-	return Feature{newFeature}
+	defer runtime.KeepAlive(feature.handle)
+	clone := C.OGR_F_Clone(feature.cval)
+	return newFeature(clone)
 }
 
 // Test if two features are the same
 func (f1 Feature) Equal(f2 Feature) bool {
+	defer runtime.KeepAlive(f1.handle)
+	defer runtime.KeepAlive(f2.handle)
 	equal := C.OGR_F_Equal(f1.cval, f2.cval)
 	return equal != 0
 }
 
 // Fetch number of fields on this feature
 func (feature Feature) FieldCount() int {
+	defer runtime.KeepAlive(feature.handle)
 	count := C.OGR_F_GetFieldCount(feature.cval)
 	return int(count)
 }
 
 // Fetch definition for the indicated field
 func (feature Feature) FieldDefinition(index int) FieldDefinition {
+	defer runtime.KeepAlive(feature.handle)
 	defn := C.OGR_F_GetFieldDefnRef(feature.cval, C.int(index))
 	return FieldDefinition{defn}
 }
 
 // Fetch the field index for the given field name
 func (feature Feature) FieldIndex(name string) int {
+	defer runtime.KeepAlive(feature.handle)
 	cName := C.CString(name)
 	defer C.free(unsafe.Pointer(cName))
 	index := C.OGR_F_GetFieldIndex(feature.cval, cName)
@@ -1053,41 +1240,57 @@ func (feature Feature) FieldIndex(name string) int {
 
 // Return if a field has ever been assigned a value
 func (feature Feature) IsFieldSet(index int) bool {
+	defer runtime.KeepAlive(feature.handle)
 	set := C.OGR_F_IsFieldSet(feature.cval, C.int(index))
 	return set != 0
 }
 
 // Clear a field and mark it as unset
 func (feature Feature) UnnsetField(index int) {
+	defer runtime.KeepAlive(feature.handle)
 	C.OGR_F_UnsetField(feature.cval, C.int(index))
 }
 
 // Fetch a reference to the internal field value
 func (feature Feature) RawField(index int) Field {
+	defer runtime.KeepAlive(feature.handle)
 	field := C.OGR_F_GetRawFieldRef(feature.cval, C.int(index))
 	return Field{field}
 }
 
 // Fetch field value as integer
 func (feature Feature) FieldAsInteger(index int) int {
+	defer runtime.KeepAlive(feature.handle)
 	val := C.OGR_F_GetFieldAsInteger(feature.cval, C.int(index))
 	return int(val)
 }
 
+// Fetch field value as a 64 bit integer. Requires GDAL 2.0.
+func (feature Feature) FieldAsInteger64(index int) (int64, error) {
+	defer runtime.KeepAlive(feature.handle)
+	defer pinErrorState()()
+	var val C.longlong
+	err := C.goFeatureFieldAsInteger64(feature.cval, C.int(index), &val).Err()
+	return int64(val), err
+}
+
 // Fetch field value as float64
 func (feature Feature) FieldAsFloat64(index int) float64 {
+	defer runtime.KeepAlive(feature.handle)
 	val := C.OGR_F_GetFieldAsDouble(feature.cval, C.int(index))
 	return float64(val)
 }
 
 // Fetch field value as string
 func (feature Feature) FieldAsString(index int) string {
+	defer runtime.KeepAlive(feature.handle)
 	val := C.OGR_F_GetFieldAsString(feature.cval, C.int(index))
 	return C.GoString(val)
 }
 
 // Fetch field as list of integers
 func (feature Feature) FieldAsIntegerList(index int) []int {
+	defer runtime.KeepAlive(feature.handle)
 	var count int
 	cArray := C.OGR_F_GetFieldAsIntegerList(feature.cval, C.int(index), (*C.int)(unsafe.Pointer(&count)))
 	var goSlice []int
@@ -1100,6 +1303,7 @@ func (feature Feature) FieldAsIntegerList(index int) []int {
 
 // Fetch field as list of float64
 func (feature Feature) FieldAsFloat64List(index int) []float64 {
+	defer runtime.KeepAlive(feature.handle)
 	var count int
 	cArray := C.OGR_F_GetFieldAsDoubleList(feature.cval, C.int(index), (*C.int)(unsafe.Pointer(&count)))
 	var goSlice []float64
@@ -1112,6 +1316,7 @@ func (feature Feature) FieldAsFloat64List(index int) []float64 {
 
 // Fetch field as list of strings
 func (feature Feature) FieldAsStringList(index int) []string {
+	defer runtime.KeepAlive(feature.handle)
 	p := C.OGR_F_GetFieldAsStringList(feature.cval, C.int(index))
 
 	var strings []string
@@ -1130,6 +1335,7 @@ func (feature Feature) FieldAsStringList(index int) []string {
 
 // Fetch field as binary data
 func (feature Feature) FieldAsBinary(index int) []uint8 {
+	defer runtime.KeepAlive(feature.handle)
 	var count int
 	cArray := C.OGR_F_GetFieldAsBinary(feature.cval, C.int(index), (*C.int)(unsafe.Pointer(&count)))
 	var goSlice []uint8
@@ -1142,6 +1348,7 @@ func (feature Feature) FieldAsBinary(index int) []uint8 {
 
 // Fetch field as date and time
 func (feature Feature) FieldAsDateTime(index int) (time.Time, bool) {
+	defer runtime.KeepAlive(feature.handle)
 	var year, month, day, hour, minute, second, tzFlag int
 	success := C.OGR_F_GetFieldAsDateTime(
 		feature.cval,
@@ -1160,16 +1367,26 @@ func (feature Feature) FieldAsDateTime(index int) (time.Time, bool) {
 
 // Set field to integer value
 func (feature Feature) SetFieldInteger(index, value int) {
+	defer runtime.KeepAlive(feature.handle)
 	C.OGR_F_SetFieldInteger(feature.cval, C.int(index), C.int(value))
 }
 
+// Set field to 64 bit integer value. Requires GDAL 2.0.
+func (feature Feature) SetFieldInteger64(index int, value int64) error {
+	defer runtime.KeepAlive(feature.handle)
+	defer pinErrorState()()
+	return C.goFeatureSetFieldInteger64(feature.cval, C.int(index), C.longlong(value)).Err()
+}
+
 // Set field to float64 value
 func (feature Feature) SetFieldFloat64(index int, value float64) {
+	defer runtime.KeepAlive(feature.handle)
 	C.OGR_F_SetFieldDouble(feature.cval, C.int(index), C.double(value))
 }
 
 // Set field to string value
 func (feature Feature) SetFieldString(index int, value string) {
+	defer runtime.KeepAlive(feature.handle)
 	cVal := C.CString(value)
 	defer C.free(unsafe.Pointer(cVal))
 	C.OGR_F_SetFieldString(feature.cval, C.int(index), cVal)
@@ -1177,6 +1394,7 @@ func (feature Feature) SetFieldString(index int, value string) {
 
 // Set field to list of integers
 func (feature Feature) SetFieldIntegerList(index int, value []int) {
+	defer runtime.KeepAlive(feature.handle)
 	C.OGR_F_SetFieldIntegerList(
 		feature.cval,
 		C.int(index),
@@ -1187,6 +1405,7 @@ func (feature Feature) SetFieldIntegerList(index int, value []int) {
 
 // Set field to list of float64
 func (feature Feature) SetFieldFloat64List(index int, value []float64) {
+	defer runtime.KeepAlive(feature.handle)
 	C.OGR_F_SetFieldDoubleList(
 		feature.cval,
 		C.int(index),
@@ -1197,6 +1416,7 @@ func (feature Feature) SetFieldFloat64List(index int, value []float64) {
 
 // Set field to list of strings
 func (feature Feature) SetFieldStringList(index int, value []string) {
+	defer runtime.KeepAlive(feature.handle)
 	length := len(value)
 	cValue := make([]*C.char, length+1)
 	for i := 0; i < length; i++ {
@@ -1214,11 +1434,13 @@ func (feature Feature) SetFieldStringList(index int, value []string) {
 
 // Set field from the raw field pointer
 func (feature Feature) SetFieldRaw(index int, field Field) {
+	defer runtime.KeepAlive(feature.handle)
 	C.OGR_F_SetFieldRaw(feature.cval, C.int(index), field.cval)
 }
 
 // Set field as binary data
 func (feature Feature) SetFieldBinary(index int, value []uint8) {
+	defer runtime.KeepAlive(feature.handle)
 	C.OGR_F_SetFieldBinary(
 		feature.cval,
 		C.int(index),
@@ -1229,6 +1451,7 @@ func (feature Feature) SetFieldBinary(index int, value []uint8) {
 
 // Set field as date / time
 func (feature Feature) SetFieldDateTime(index int, dt time.Time) {
+	defer runtime.KeepAlive(feature.handle)
 	C.OGR_F_SetFieldDateTime(
 		feature.cval,
 		C.int(index),
@@ -1244,24 +1467,33 @@ func (feature Feature) SetFieldDateTime(index int, dt time.Time) {
 
 // Fetch feature indentifier
 func (feature Feature) FID() int {
+	defer runtime.KeepAlive(feature.handle)
 	fid := C.OGR_F_GetFID(feature.cval)
 	return int(fid)
 }
 
 // Set feature identifier
 func (feature Feature) SetFID(fid int) error {
-	return C.OGR_F_SetFID(feature.cval, C.long(fid)).Err()
+	defer runtime.KeepAlive(feature.handle)
+	defer pinErrorState()()
+	return C.OGR_F_SetFID(feature.cval, C.goFID(fid)).Err()
 }
 
 // Unimplemented: DumpReadable
 
 // Set one feature from another
 func (this Feature) SetFrom(other Feature, forgiving int) error {
+	defer runtime.KeepAlive(this.handle)
+	defer runtime.KeepAlive(other.handle)
+	defer pinErrorState()()
 	return C.OGR_F_SetFrom(this.cval, other.cval, C.int(forgiving)).Err()
 }
 
 // Set one feature from another, using field map
 func (this Feature) SetFromWithMap(other Feature, forgiving int, fieldMap []int) error {
+	defer runtime.KeepAlive(this.handle)
+	defer runtime.KeepAlive(other.handle)
+	defer pinErrorState()()
 	return C.OGR_F_SetFromWithMap(
 		this.cval,
 		other.cval,
@@ -1272,12 +1504,14 @@ func (this Feature) SetFromWithMap(other Feature, forgiving int, fieldMap []int)
 
 // Fetch style string for this feature
 func (feature Feature) StlyeString() string {
+	defer runtime.KeepAlive(feature.handle)
 	style := C.OGR_F_GetStyleString(feature.cval)
 	return C.GoString(style)
 }
 
 // Set style string for this feature
 func (feature Feature) SetStyleString(style string) {
+	defer runtime.KeepAlive(feature.handle)
 	cStyle := C.CString(style)
 	C.OGR_F_SetStyleStringDirectly(feature.cval, cStyle)
 }
@@ -1288,33 +1522,46 @@ func (feature Feature) SetStyleString(style string) {
 
 type Layer struct {
 	cval C.OGRLayerH
+	// Handle of the data source owning the layer
+	dataSource *handle
+}
+
+// Return true if this layer wraps a NULL handle
+func (layer Layer) IsNil() bool {
+	return layer.cval == nil
 }
 
 // Return the layer name
 func (layer Layer) Name() string {
+	defer runtime.KeepAlive(layer.dataSource)
 	name := C.OGR_L_GetName(layer.cval)
 	return C.GoString(name)
 }
 
 // Return the layer geometry type
 func (layer Layer) Type() GeometryType {
+	defer runtime.KeepAlive(layer.dataSource)
 	gt := C.OGR_L_GetGeomType(layer.cval)
 	return GeometryType(gt)
 }
 
 // Return the current spatial filter for this layer
 func (layer Layer) SpatialFilter() Geometry {
+	defer runtime.KeepAlive(layer.dataSource)
 	geom := C.OGR_L_GetSpatialFilter(layer.cval)
-	return Geometry{geom}
+	return Geometry{geom, borrowHandle(layer.dataSource)}
 }
 
 // Set a new spatial filter for this layer
 func (layer Layer) SetSpatialFilter(filter Geometry) {
+	defer runtime.KeepAlive(layer.dataSource)
+	defer runtime.KeepAlive(filter.handle)
 	C.OGR_L_SetSpatialFilter(layer.cval, filter.cval)
 }
 
 // Set a new rectangular spatial filter for this layer
 func (layer Layer) SetSpatialFilterRect(minX, minY, maxX, maxY float64) {
+	defer runtime.KeepAlive(layer.dataSource)
 	C.OGR_L_SetSpatialFilterRect(
 		layer.cval,
 		C.double(minX), C.double(minY), C.double(maxX), C.double(maxY),
@@ -1323,6 +1570,8 @@ func (layer Layer) SetSpatialFilterRect(minX, minY, maxX, maxY float64) {
 
 // Set a new attribute query filter
 func (layer Layer) SetAttributeFilter(filter string) error {
+	defer runtime.KeepAlive(layer.dataSource)
+	defer pinErrorState()()
 	cFilter := C.CString(filter)
 	defer C.free(unsafe.Pointer(cFilter))
 	return C.OGR_L_SetAttributeFilter(layer.cval, cFilter).Err()
@@ -1330,67 +1579,86 @@ func (layer Layer) SetAttributeFilter(filter string) error {
 
 // Reset reading to start on the first featre
 func (layer Layer) ResetReading() {
+	defer runtime.KeepAlive(layer.dataSource)
 	C.OGR_L_ResetReading(layer.cval)
 }
 
 // Fetch the next available feature from this layer
 func (layer Layer) NextFeature() Feature {
+	defer runtime.KeepAlive(layer.dataSource)
 	feature := C.OGR_L_GetNextFeature(layer.cval)
-	return Feature{feature}
+	return newFeature(feature)
 }
 
 // Move read cursor to the provided index
 func (layer Layer) SetNextByIndex(index int) error {
-	return C.OGR_L_SetNextByIndex(layer.cval, C.long(index)).Err()
+	defer runtime.KeepAlive(layer.dataSource)
+	defer pinErrorState()()
+	return C.OGR_L_SetNextByIndex(layer.cval, C.goFID(index)).Err()
 }
 
 // Fetch a feature by its index
 func (layer Layer) Feature(index int) Feature {
-	feature := C.OGR_L_GetFeature(layer.cval, C.long(index))
-	return Feature{feature}
+	defer runtime.KeepAlive(layer.dataSource)
+	feature := C.OGR_L_GetFeature(layer.cval, C.goFID(index))
+	return newFeature(feature)
 }
 
 // Rewrite the provided feature
 func (layer Layer) SetFeature(feature Feature) error {
+	defer runtime.KeepAlive(layer.dataSource)
+	defer runtime.KeepAlive(feature.handle)
+	defer pinErrorState()()
 	return C.OGR_L_SetFeature(layer.cval, feature.cval).Err()
 }
 
 // Create and write a new feature within a layer
 func (layer Layer) Create(feature Feature) error {
+	defer runtime.KeepAlive(layer.dataSource)
+	defer runtime.KeepAlive(feature.handle)
+	defer pinErrorState()()
 	return C.OGR_L_CreateFeature(layer.cval, feature.cval).Err()
 }
 
 // Delete indicated feature from layer
 func (layer Layer) Delete(index int) error {
-	return C.OGR_L_DeleteFeature(layer.cval, C.long(index)).Err()
+	defer runtime.KeepAlive(layer.dataSource)
+	defer pinErrorState()()
+	return C.OGR_L_DeleteFeature(layer.cval, C.goFID(index)).Err()
 }
 
 // Fetch the schema information for this layer
 func (layer Layer) Definition() FeatureDefinition {
+	defer runtime.KeepAlive(layer.dataSource)
 	defn := C.OGR_L_GetLayerDefn(layer.cval)
 	return FeatureDefinition{defn}
 }
 
 // Fetch the spatial reference system for this layer
 func (layer Layer) SpatialReference() SpatialReference {
+	defer runtime.KeepAlive(layer.dataSource)
 	sr := C.OGR_L_GetSpatialRef(layer.cval)
-	return SpatialReference{sr}
+	return SpatialReference{sr, borrowHandle(layer.dataSource)}
 }
 
 // Fetch the feature count for this layer
 func (layer Layer) FeatureCount(force bool) (count int, ok bool) {
+	defer runtime.KeepAlive(layer.dataSource)
 	count = int(C.OGR_L_GetFeatureCount(layer.cval, BoolToCInt(force)))
 	return count, count != -1
 }
 
 // Fetch the extent of this layer
 func (layer Layer) Extent(force bool) (env Envelope, err error) {
+	defer runtime.KeepAlive(layer.dataSource)
+	defer pinErrorState()()
 	err = C.OGR_L_GetExtent(layer.cval, &env.cval, BoolToCInt(force)).Err()
 	return
 }
 
 // Test if this layer supports the named capability
 func (layer Layer) TestCapability(capability string) bool {
+	defer runtime.KeepAlive(layer.dataSource)
 	cString := C.CString(capability)
 	defer C.free(unsafe.Pointer(cString))
 	val := C.OGR_L_TestCapability(layer.cval, cString)
@@ -1399,63 +1667,85 @@ func (layer Layer) TestCapability(capability string) bool {
 
 // Create a new field on a layer
 func (layer Layer) CreateField(fd FieldDefinition, approxOK bool) error {
+	defer runtime.KeepAlive(layer.dataSource)
+	defer pinErrorState()()
 	return C.OGR_L_CreateField(layer.cval, fd.cval, BoolToCInt(approxOK)).Err()
 }
 
 // Delete a field from the layer
 func (layer Layer) DeleteField(index int) error {
+	defer runtime.KeepAlive(layer.dataSource)
+	defer pinErrorState()()
 	return C.OGR_L_DeleteField(layer.cval, C.int(index)).Err()
 }
 
 // Reorder all the fields of a layer
 func (layer Layer) ReorderFields(layerMap []int) error {
+	defer runtime.KeepAlive(layer.dataSource)
+	defer pinErrorState()()
 	return C.OGR_L_ReorderFields(layer.cval, (*C.int)(unsafe.Pointer(&layerMap[0]))).Err()
 }
 
 // Reorder an existing field of a layer
 func (layer Layer) ReorderField(oldIndex, newIndex int) error {
+	defer runtime.KeepAlive(layer.dataSource)
+	defer pinErrorState()()
 	return C.OGR_L_ReorderField(layer.cval, C.int(oldIndex), C.int(newIndex)).Err()
 }
 
 // Alter the definition of an existing field of a layer
 func (layer Layer) AlterFieldDefn(index int, newDefn FieldDefinition, flags int) error {
+	defer runtime.KeepAlive(layer.dataSource)
+	defer pinErrorState()()
 	return C.OGR_L_AlterFieldDefn(layer.cval, C.int(index), newDefn.cval, C.int(flags)).Err()
 }
 
 // Begin a transation on data sources which support it
 func (layer Layer) StartTransaction() error {
+	defer runtime.KeepAlive(layer.dataSource)
+	defer pinErrorState()()
 	return C.OGR_L_StartTransaction(layer.cval).Err()
 }
 
 // Commit a transaction on data sources which support it
 func (layer Layer) CommitTransaction() error {
+	defer runtime.KeepAlive(layer.dataSource)
+	defer pinErrorState()()
 	return C.OGR_L_CommitTransaction(layer.cval).Err()
 }
 
 // Roll back the current transaction on data sources which support it
 func (layer Layer) RollbackTransaction() error {
+	defer runtime.KeepAlive(layer.dataSource)
+	defer pinErrorState()()
 	return C.OGR_L_RollbackTransaction(layer.cval).Err()
 }
 
 // Flush pending changes to the layer
 func (layer Layer) Sync() error {
+	defer runtime.KeepAlive(layer.dataSource)
+	defer pinErrorState()()
 	return C.OGR_L_SyncToDisk(layer.cval).Err()
 }
 
 // Fetch the name of the FID column
 func (layer Layer) FIDColumn() string {
+	defer runtime.KeepAlive(layer.dataSource)
 	name := C.OGR_L_GetFIDColumn(layer.cval)
 	return C.GoString(name)
 }
 
 // Fetch the name of the geometry column
 func (layer Layer) GeometryColumn() string {
+	defer runtime.KeepAlive(layer.dataSource)
 	name := C.OGR_L_GetGeometryColumn(layer.cval)
 	return C.GoString(name)
 }
 
 // Set which fields can be ignored when retrieving features from the layer
 func (layer Layer) SetIgnoredFields(names []string) error {
+	defer runtime.KeepAlive(layer.dataSource)
+	defer pinErrorState()()
 	length := len(names)
 	cNames := make([]*C.char, length+1)
 	for i := 0; i < length; i++ {
@@ -1501,27 +1791,66 @@ func (layer Layer) SetIgnoredFields(names []string) error {
 
 type DataSource struct {
 	cval C.OGRDataSourceH
+	*handle
+}
+
+// Return true if this data source wraps a NULL handle or has been closed
+func (ds DataSource) IsNil() bool {
+	return ds.cval == nil || ds.isClosed()
+}
+
+func newDataSource(h C.OGRDataSourceH) DataSource {
+	return DataSource{h, ownHandle("DataSource", h, func(h C.OGRDataSourceH) error {
+		C.OGR_DS_Destroy(h)
+		return nil
+	})}
+}
+
+func newSharedDataSource(h C.OGRDataSourceH) DataSource {
+	return DataSource{h, ownHandle("DataSource", h, func(h C.OGRDataSourceH) error {
+		defer pinErrorState()()
+		return C.OGRReleaseDataSource(h).Err()
+	})}
+}
+
+// Access the vector layers of a dataset opened with OpenEx. Requires GDAL
+// 2.0 or later, where every dataset is a data source; with GDAL 1.x the
+// result is nil. The data source is borrowed from the dataset.
+func (dataset Dataset) DataSource() DataSource {
+	defer runtime.KeepAlive(dataset.handle)
+	if VERSION_MAJOR < 2 {
+		return DataSource{}
+	}
+	return DataSource{C.OGRDataSourceH(dataset.cval), borrowHandle(dataset.handle)}
 }
 
 // Open a file / data source with one of the registered drivers
-func OpenDataSource(name string, update int) DataSource {
+func OpenDataSource(name string, update int) (DataSource, error) {
+	defer pinErrorState()()
 	cName := C.CString(name)
 	defer C.free(unsafe.Pointer(cName))
 	ds := C.OGROpen(cName, C.int(update), nil)
-	return DataSource{ds}
+	if ds == nil {
+		return DataSource{}, nullHandleError("Error: data source '%s' open error", name)
+	}
+	return newDataSource(ds), nil
 }
 
 // Open a shared file / data source with one of the registered drivers
-func OpenSharedDataSource(name string, update int) DataSource {
+func OpenSharedDataSource(name string, update int) (DataSource, error) {
+	defer pinErrorState()()
 	cName := C.CString(name)
 	defer C.free(unsafe.Pointer(cName))
 	ds := C.OGROpenShared(cName, C.int(update), nil)
-	return DataSource{ds}
+	if ds == nil {
+		return DataSource{}, nullHandleError("Error: data source '%s' open error", name)
+	}
+	return newSharedDataSource(ds), nil
 }
 
 // Drop a reference to this datasource and destroy if reference is zero
 func (ds DataSource) Release() error {
-	return C.OGRReleaseDataSource(ds.cval).Err()
+	return ds.close()
 }
 
 // Return the number of opened data sources
@@ -1533,47 +1862,60 @@ func OpenDataSourceCount() int {
 // Return the i'th datasource opened
 func OpenDataSourceByIndex(index int) DataSource {
 	ds := C.OGRGetOpenDS(C.int(index))
-	return DataSource{ds}
+	return DataSource{ds, nil}
 }
 
 // Closes datasource and releases resources
 func (ds DataSource) Destroy() {
-	C.OGR_DS_Destroy(ds.cval)
+	ds.close()
+}
+
+// Closes datasource and releases resources. Closing a data source more
+// than once is a no-op.
+func (ds DataSource) Close() error {
+	return ds.close()
 }
 
 // Fetch the name of the data source
 func (ds DataSource) Name() string {
+	defer runtime.KeepAlive(ds.handle)
 	name := C.OGR_DS_GetName(ds.cval)
 	return C.GoString(name)
 }
 
 // Fetch the number of layers in this data source
 func (ds DataSource) LayerCount() int {
+	defer runtime.KeepAlive(ds.handle)
 	count := C.OGR_DS_GetLayerCount(ds.cval)
 	return int(count)
 }
 
 // Fetch a layer of this data source by index
 func (ds DataSource) LayerByIndex(index int) Layer {
+	defer runtime.KeepAlive(ds.handle)
 	layer := C.OGR_DS_GetLayer(ds.cval, C.int(index))
-	return Layer{layer}
+	return Layer{layer, ds.handle}
 }
 
 // Fetch a layer of this data source by name
 func (ds DataSource) LayerByName(name string) Layer {
+	defer runtime.KeepAlive(ds.handle)
 	cString := C.CString(name)
 	defer C.free(unsafe.Pointer(cString))
 	layer := C.OGR_DS_GetLayerByName(ds.cval, cString)
-	return Layer{layer}
+	return Layer{layer, ds.handle}
 }
 
 // Delete the layer from the data source
 func (ds DataSource) Delete(index int) error {
+	defer runtime.KeepAlive(ds.handle)
+	defer pinErrorState()()
 	return C.OGR_DS_DeleteLayer(ds.cval, C.int(index)).Err()
 }
 
 // Fetch the driver that the data source was opened with
 func (ds DataSource) Driver() OGRDriver {
+	defer runtime.KeepAlive(ds.handle)
 	driver := C.OGR_DS_GetDriver(ds.cval)
 	return OGRDriver{driver}
 }
@@ -1585,25 +1927,21 @@ func (ds DataSource) CreateLayer(
 	geomType GeometryType,
 	options []string,
 ) Layer {
+	defer runtime.KeepAlive(ds.handle)
+	defer runtime.KeepAlive(sr.handle)
 	cName := C.CString(name)
 	defer C.free(unsafe.Pointer(cName))
-
-	length := len(options)
-	opts := make([]*C.char, length+1)
-	for i := 0; i < length; i++ {
-		opts[i] = C.CString(options[i])
-		defer C.free(unsafe.Pointer(opts[i]))
-	}
-	opts[length] = (*C.char)(unsafe.Pointer(nil))
+	opts, free := cStringList(options)
+	defer free()
 
 	layer := C.OGR_DS_CreateLayer(
 		ds.cval,
 		cName,
 		sr.cval,
 		C.OGRwkbGeometryType(geomType),
-		(**C.char)(unsafe.Pointer(&opts[0])),
+		opts,
 	)
-	return Layer{layer}
+	return Layer{layer, ds.handle}
 }
 
 // Duplicate an existing layer
@@ -1612,28 +1950,20 @@ func (ds DataSource) CopyLayer(
 	name string,
 	options []string,
 ) Layer {
+	defer runtime.KeepAlive(ds.handle)
+	defer runtime.KeepAlive(source.dataSource)
 	cName := C.CString(name)
 	defer C.free(unsafe.Pointer(cName))
+	opts, free := cStringList(options)
+	defer free()
 
-	length := len(options)
-	opts := make([]*C.char, length+1)
-	for i := 0; i < length; i++ {
-		opts[i] = C.CString(options[i])
-		defer C.free(unsafe.Pointer(opts[i]))
-	}
-	opts[length] = (*C.char)(unsafe.Pointer(nil))
-
-	layer := C.OGR_DS_CopyLayer(
-		ds.cval,
-		source.cval,
-		cName,
-		(**C.char)(unsafe.Pointer(&opts[0])),
-	)
-	return Layer{layer}
+	layer := C.OGR_DS_CopyLayer(ds.cval, source.cval, cName, opts)
+	return Layer{layer, ds.handle}
 }
 
 // Test if the data source has the indicated capability
 func (ds DataSource) TestCapability(capability string) bool {
+	defer runtime.KeepAlive(ds.handle)
 	cString := C.CString(capability)
 	defer C.free(unsafe.Pointer(cString))
 	val := C.OGR_DS_TestCapability(ds.cval, cString)
@@ -1642,22 +1972,28 @@ func (ds DataSource) TestCapability(capability string) bool {
 
 // Execute an SQL statement against the data source
 func (ds DataSource) ExecuteSQL(sql string, filter Geometry, dialect string) Layer {
+	defer runtime.KeepAlive(ds.handle)
+	defer runtime.KeepAlive(filter.handle)
 	cSQL := C.CString(sql)
 	defer C.free(unsafe.Pointer(cSQL))
 	cDialect := C.CString(dialect)
 	defer C.free(unsafe.Pointer(cDialect))
 
 	layer := C.OGR_DS_ExecuteSQL(ds.cval, cSQL, filter.cval, cDialect)
-	return Layer{layer}
+	return Layer{layer, ds.handle}
 }
 
 // Release the results of ExecuteSQL
 func (ds DataSource) ReleaseResultSet(layer Layer) {
+	defer runtime.KeepAlive(ds.handle)
+	defer runtime.KeepAlive(layer.dataSource)
 	C.OGR_DS_ReleaseResultSet(ds.cval, layer.cval)
 }
 
 // Flush pending changes to the data source
 func (ds DataSource) Sync() error {
+	defer runtime.KeepAlive(ds.handle)
+	defer pinErrorState()()
 	return C.OGR_DS_SyncToDisk(ds.cval).Err()
 }
 
@@ -1669,6 +2005,11 @@ type OGRDriver struct {
 	cval C.OGRSFDriverH
 }
 
+// Return true if this driver wraps a NULL handle
+func (driver OGRDriver) IsNil() bool {
+	return driver.cval == nil
+}
+
 // Fetch name of driver (file format)
 func (driver OGRDriver) Name() string {
 	name := C.OGR_Dr_GetName(driver.cval)
@@ -1680,7 +2021,7 @@ func (driver OGRDriver) Open(filename string, update int) (newDS DataSource, ok
 	cFilename := C.CString(filename)
 	defer C.free(unsafe.Pointer(cFilename))
 	ds := C.OGR_Dr_Open(driver.cval, cFilename, C.int(update))
-	return DataSource{ds}, ds != nil
+	return newDataSource(ds), ds != nil
 }
 
 // Test if this driver supports the named capability
@@ -1705,11 +2046,12 @@ func (driver OGRDriver) Create(name string, options []string) (newDS DataSource,
 	opts[length] = (*C.char)(unsafe.Pointer(nil))
 
 	ds := C.OGR_Dr_CreateDataSource(driver.cval, cName, (**C.char)(unsafe.Pointer(&opts[0])))
-	return DataSource{ds}, ds != nil
+	return newDataSource(ds), ds != nil
 }
 
 // Create a new datasource with this driver by copying all layers of the existing datasource
 func (driver OGRDriver) Copy(source DataSource, name string, options []string) (newDS DataSource, ok bool) {
+	defer runtime.KeepAlive(source.handle)
 	cName := C.CString(name)
 	defer C.free(unsafe.Pointer(cName))
 
@@ -1722,11 +2064,12 @@ func (driver OGRDriver) Copy(source DataSource, name string, options []string) (
 	opts[length] = (*C.char)(unsafe.Pointer(nil))
 
 	ds := C.OGR_Dr_CopyDataSource(driver.cval, source.cval, cName, (**C.char)(unsafe.Pointer(&opts[0])))
-	return DataSource{ds}, ds != nil
+	return newDataSource(ds), ds != nil
 }
 
 // Delete a data source
 func (driver OGRDriver) Delete(filename string) error {
+	defer pinErrorState()()
 	cFilename := C.CString(filename)
 	defer C.free(unsafe.Pointer(cFilename))
 	return C.OGR_Dr_DeleteDataSource(driver.cval, cFilename).Err()
@@ -1770,14 +2113,29 @@ type StyleMgr struct {
 	cval C.OGRStyleMgrH
 }
 
+// Return true if this style manager wraps a NULL handle
+func (sm StyleMgr) IsNil() bool {
+	return sm.cval == nil
+}
+
 type StyleTool struct {
 	cval C.OGRStyleToolH
 }
 
+// Return true if this style tool wraps a NULL handle
+func (st StyleTool) IsNil() bool {
+	return st.cval == nil
+}
+
 type StyleTable struct {
 	cval C.OGRStyleTableH
 }
 
+// Return true if this style table wraps a NULL handle
+func (st StyleTable) IsNil() bool {
+	return st.cval == nil
+}
+
 // Unimplemented: CreateStyleManager
 
 // Unimplemented: Destroy
@@ -0,0 +1,187 @@
+package gdal
+
+/*
+#include "go_gdal.h"
+*/
+import "C"
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/* --------------------------------------------- */
+/* Creation options                              */
+/* --------------------------------------------- */
+
+// OptionDefinition describes one option of a driver as listed in
+// DMD_CREATIONOPTIONLIST
+type OptionDefinition struct {
+	Name        string   `xml:"name,attr"`
+	Type        string   `xml:"type,attr"`
+	Description string   `xml:"description,attr"`
+	Default     string   `xml:"default,attr"`
+	Min         string   `xml:"min,attr"`
+	Max         string   `xml:"max,attr"`
+	Values      []string `xml:"Value"`
+}
+
+// ParseOptionList parses an option list such as the value of
+// DMD_CREATIONOPTIONLIST
+func ParseOptionList(list string) ([]OptionDefinition, error) {
+	if strings.TrimSpace(list) == "" {
+		return nil, nil
+	}
+	var doc struct {
+		Options []OptionDefinition `xml:"Option"`
+	}
+	if err := xml.Unmarshal([]byte(list), &doc); err != nil {
+		return nil, fmt.Errorf("invalid option list: %w", err)
+	}
+	for i := range doc.Options {
+		for j, value := range doc.Options[i].Values {
+			doc.Options[i].Values[j] = strings.TrimSpace(value)
+		}
+	}
+	return doc.Options, nil
+}
+
+// Fetch the creation options supported by this driver
+func (driver Driver) CreationOptions() ([]OptionDefinition, error) {
+	return ParseOptionList(driver.MetadataItem(DMD_CREATIONOPTIONLIST, ""))
+}
+
+// Check creation options against the list published by the driver and
+// with GDALValidateCreationOptions, returning an error that lists every
+// unknown key and bad value
+func (driver Driver) ValidateCreationOptions(options []string) error {
+	definitions, err := driver.CreationOptions()
+	if err != nil {
+		return err
+	}
+	problems := checkOptions(definitions, options)
+
+	defer pinErrorState()()
+	cOptions, free := cStringList(options)
+	defer free()
+	valid := C.GDALValidateCreationOptions(driver.cval, cOptions) != 0
+	if !valid && len(problems) == 0 {
+		msg := lastError(CE_Failure).Msg
+		if msg == "" {
+			msg = "rejected by GDAL"
+		}
+		problems = append(problems, msg)
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf(
+		"invalid creation options for %s: %s",
+		driver.ShortName(), strings.Join(problems, "; "),
+	)
+}
+
+// checkOptions returns a description of every option in options that is
+// unknown or has a value not allowed by its definition
+func checkOptions(definitions []OptionDefinition, options []string) []string {
+	byName := make(map[string]OptionDefinition, len(definitions))
+	for _, def := range definitions {
+		byName[strings.ToUpper(def.Name)] = def
+	}
+
+	var problems []string
+	for _, option := range options {
+		key, value, ok := strings.Cut(option, "=")
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%q is not KEY=VALUE", option))
+			continue
+		}
+		def, ok := byName[strings.ToUpper(key)]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("unknown option %s", key))
+			continue
+		}
+		if err := def.check(value); err != nil {
+			problems = append(problems, fmt.Sprintf("%s=%s: %v", key, value, err))
+		}
+	}
+	return problems
+}
+
+// check reports whether value is allowed for the option
+func (def OptionDefinition) check(value string) error {
+	switch strings.ToLower(def.Type) {
+	case "int", "integer", "unsigned int":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("not an integer")
+		}
+		return def.checkRange(float64(n))
+	case "float":
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("not a number")
+		}
+		return def.checkRange(f)
+	case "boolean":
+		switch strings.ToUpper(value) {
+		case "YES", "NO", "TRUE", "FALSE", "ON", "OFF", "1", "0":
+			return nil
+		}
+		return fmt.Errorf("not a boolean")
+	case "string-select":
+		for _, allowed := range def.Values {
+			if strings.EqualFold(allowed, value) {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be one of %s", strings.Join(def.Values, ", "))
+	}
+	return nil
+}
+
+func (def OptionDefinition) checkRange(value float64) error {
+	if def.Min != "" {
+		if min, err := strconv.ParseFloat(def.Min, 64); err == nil && value < min {
+			return fmt.Errorf("below minimum %s", def.Min)
+		}
+	}
+	if def.Max != "" {
+		if max, err := strconv.ParseFloat(def.Max, 64); err == nil && value > max {
+			return fmt.Errorf("above maximum %s", def.Max)
+		}
+	}
+	return nil
+}
+
+// CreationOptions builds the KEY=VALUE list passed to Driver.Create and
+// Driver.CreateCopy
+type CreationOptions map[string]string
+
+// Set an option, formatting bools as YES/NO and other values with %v
+func (opts CreationOptions) Set(key string, value interface{}) CreationOptions {
+	switch v := value.(type) {
+	case bool:
+		if v {
+			opts[key] = "YES"
+		} else {
+			opts[key] = "NO"
+		}
+	default:
+		opts[key] = fmt.Sprint(v)
+	}
+	return opts
+}
+
+// Strings returns the options as KEY=VALUE strings sorted by key
+func (opts CreationOptions) Strings() []string {
+	list := make([]string, 0, len(opts))
+	for key, value := range opts {
+		list = append(list, key+"="+value)
+	}
+	sort.Strings(list)
+	return list
+}
@@ -2,17 +2,11 @@ package gdal
 
 /*
 #include "go_gdal.h"
-#include "gdal_version.h"
-
-#cgo darwin pkg-config: gdal
-#cgo linux LDFLAGS: -L/usr/local/lib -lgdal
-#cgo linux CFLAGS: -I/usr/local/include
-#cgo windows LDFLAGS: -LC:/Python27/Lib/site-packages/osgeo/lib -lgdal_i
-#cgo windows CFLAGS: -IC:/Python27/Lib/site-packages/osgeo/include/gdal
 */
 import "C"
 import (
 	"reflect"
+	"runtime"
 	"unsafe"
 )
 
@@ -22,18 +16,49 @@ import (
 
 type SpatialReference struct {
 	cval C.OGRSpatialReferenceH
+	*handle
+}
+
+// Return true if this spatial reference wraps a NULL handle or has been
+// released
+func (sr SpatialReference) IsNil() bool {
+	return sr.cval == nil || sr.isClosed()
+}
+
+// Spatial references are reference counted by the objects using them, so
+// an owned handle releases its reference rather than destroying.
+func newSpatialReference(h C.OGRSpatialReferenceH) SpatialReference {
+	return SpatialReference{h, ownHandle("SpatialReference", h, func(h C.OGRSpatialReferenceH) error {
+		C.OSRRelease(h)
+		return nil
+	})}
 }
 
+// AxisMappingStrategy selects how the axes of a spatial reference map to
+// the x and y of coordinates. Setting it requires GDAL 3.0.
+type AxisMappingStrategy int
+
+const (
+	// Longitude or easting first, whatever the authority says
+	OAMS_TraditionalGISOrder = AxisMappingStrategy(0)
+	// Axis order defined by the authority, e.g. latitude first for EPSG:4326
+	OAMS_AuthorityCompliant = AxisMappingStrategy(1)
+	// Order set with OSRSetDataAxisToSRSAxisMapping
+	OAMS_Custom = AxisMappingStrategy(2)
+)
+
 // Create a new SpatialReference
 func CreateSpatialReference(wkt string) SpatialReference {
 	cString := C.CString(wkt)
 	defer C.free(unsafe.Pointer(cString))
 	sr := C.OSRNewSpatialReference(cString)
-	return SpatialReference{sr}
+	return newSpatialReference(sr)
 }
 
 // Initialize SRS based on WKT string
 func (sr SpatialReference) FromWKT(wkt string) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	cString := C.CString(wkt)
 	defer C.free(unsafe.Pointer(cString))
 	return C.OSRImportFromWkt(sr.cval, &cString).Err()
@@ -41,6 +66,8 @@ func (sr SpatialReference) FromWKT(wkt string) error {
 
 // Export coordinate system to WKT
 func (sr SpatialReference) ToWKT() (string, error) {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	var p *C.char
 	err := C.OSRExportToWkt(sr.cval, &p).Err()
 	wkt := C.GoString(p)
@@ -49,6 +76,8 @@ func (sr SpatialReference) ToWKT() (string, error) {
 
 // Export coordinate system to a nicely formatted WKT string
 func (sr SpatialReference) ToPrettyWKT(simplify bool) (string, error) {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	var p *C.char
 	err := C.OSRExportToPrettyWkt(
 		sr.cval, &p, BoolToCInt(simplify),
@@ -59,70 +88,118 @@ func (sr SpatialReference) ToPrettyWKT(simplify bool) (string, error) {
 
 // Initialize SRS based on EPSG code
 func (sr SpatialReference) FromEPSG(code int) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRImportFromEPSG(sr.cval, C.int(code)).Err()
 }
 
 // Initialize SRS based on EPSG code, using EPSG lat/long ordering
 func (sr SpatialReference) FromEPSGA(code int) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRImportFromEPSGA(sr.cval, C.int(code)).Err()
 }
 
-// Destroy the spatial reference
+// Drop the reference held by this spatial reference, as Close does.  Unlike
+// OSRDestroySpatialReference this never deletes an object that geometries
+// or layers still reference, and it is a no-op for borrowed references.
 func (sr SpatialReference) Destroy() {
-	C.OSRDestroySpatialReference(sr.cval)
+	sr.close()
+}
+
+// Release the spatial reference; a no-op for references borrowed from a
+// geometry or layer
+func (sr SpatialReference) Close() error {
+	return sr.close()
 }
 
 // Make a duplicate of this spatial reference
 func (sr SpatialReference) Clone() SpatialReference {
+	defer runtime.KeepAlive(sr.handle)
 	newSR := C.OSRClone(sr.cval)
-	return SpatialReference{newSR}
+	return newSpatialReference(newSR)
 }
 
 // Make a duplicate of the GEOGCS node of this spatial reference
 func (sr SpatialReference) CloneGeogCS() SpatialReference {
+	defer runtime.KeepAlive(sr.handle)
 	newSR := C.OSRCloneGeogCS(sr.cval)
-	return SpatialReference{newSR}
+	return newSpatialReference(newSR)
 }
 
 // Increments the reference count by one, returning reference count
 func (sr SpatialReference) Reference() int {
+	defer runtime.KeepAlive(sr.handle)
 	count := C.OSRReference(sr.cval)
 	return int(count)
 }
 
 // Decrements the reference count by one, returning reference count
 func (sr SpatialReference) Dereference() int {
+	defer runtime.KeepAlive(sr.handle)
 	count := C.OSRDereference(sr.cval)
 	return int(count)
 }
 
-// Decrements the reference count by one and destroy if zero
+// Decrements the reference count by one and destroy if zero.  The first
+// Release of an owned spatial reference drops the reference it was created
+// with, as Close does; further calls drop references taken with Reference.
 func (sr SpatialReference) Release() {
-	C.OSRRelease(sr.cval)
+	defer runtime.KeepAlive(sr.handle)
+	if owned, _ := sr.closeOwned(); !owned {
+		C.OSRRelease(sr.cval)
+	}
+}
+
+// Set how the axes of this spatial reference map to x and y of the
+// coordinates it is used with. Requires GDAL 3.0.
+func (sr SpatialReference) SetAxisMappingStrategy(strategy AxisMappingStrategy) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
+	return C.goSRSSetAxisMappingStrategy(sr.cval, C.int(strategy)).Err()
+}
+
+// Fetch the axis mapping strategy of this spatial reference. Requires GDAL 3.0.
+func (sr SpatialReference) AxisMappingStrategy() (AxisMappingStrategy, error) {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
+	var strategy C.int
+	err := C.goSRSAxisMappingStrategy(sr.cval, &strategy).Err()
+	return AxisMappingStrategy(strategy), err
 }
 
 // Validate spatial reference tokens
 func (sr SpatialReference) Validate() error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRValidate(sr.cval).Err()
 }
 
 // Correct parameter ordering to match CT specification
 func (sr SpatialReference) FixupOrdering() error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRFixupOrdering(sr.cval).Err()
 }
 
 // Fix up spatial reference as needed
 func (sr SpatialReference) Fixup() error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRFixup(sr.cval).Err()
 }
 
 // Strip OGC CT parameters
 func (sr SpatialReference) StripCTParams() error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRStripCTParms(sr.cval).Err()
 }
 
 // Import PROJ.4 coordinate string
 func (sr SpatialReference) FromProj4(input string) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	cString := C.CString(input)
 	defer C.free(unsafe.Pointer(cString))
 	return C.OSRImportFromProj4(sr.cval, cString).Err()
@@ -130,6 +207,8 @@ func (sr SpatialReference) FromProj4(input string) error {
 
 // Export coordinate system in PROJ.4 format
 func (sr SpatialReference) ToProj4() (string, error) {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	var p *C.char
 	err := C.OSRExportToProj4(sr.cval, &p).Err()
 	proj4 := C.GoString(p)
@@ -138,6 +217,8 @@ func (sr SpatialReference) ToProj4() (string, error) {
 
 // Import coordinate system from ESRI .prj formats
 func (sr SpatialReference) FromESRI(input string) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	cString := C.CString(input)
 	defer C.free(unsafe.Pointer(cString))
 	return C.OSRImportFromProj4(sr.cval, cString).Err()
@@ -145,6 +226,8 @@ func (sr SpatialReference) FromESRI(input string) error {
 
 // Import coordinate system from PCI projection definition
 func (sr SpatialReference) FromPCI(proj, units string, params []float64) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	cProj := C.CString(proj)
 	defer C.free(unsafe.Pointer(cProj))
 	cUnits := C.CString(units)
@@ -160,6 +243,8 @@ func (sr SpatialReference) FromPCI(proj, units string, params []float64) error {
 
 // Import coordinate system from USGS projection definition
 func (sr SpatialReference) FromUSGS(projsys, zone int, params []float64, datum int) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRImportFromUSGS(
 		sr.cval,
 		C.long(projsys),
@@ -171,6 +256,8 @@ func (sr SpatialReference) FromUSGS(projsys, zone int, params []float64, datum i
 
 // Import coordinate system from XML format (GML only currently)
 func (sr SpatialReference) FromXML(xml string) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	cXml := C.CString(xml)
 	defer C.free(unsafe.Pointer(cXml))
 	return C.OSRImportFromXML(sr.cval, cXml).Err()
@@ -178,6 +265,8 @@ func (sr SpatialReference) FromXML(xml string) error {
 
 // Import coordinate system from ERMapper projection definitions
 func (sr SpatialReference) FromERM(proj, datum, units string) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	cProj := C.CString(proj)
 	defer C.free(unsafe.Pointer(cProj))
 	cDatum := C.CString(datum)
@@ -190,6 +279,8 @@ func (sr SpatialReference) FromERM(proj, datum, units string) error {
 
 // Import coordinate system from a URL
 func (sr SpatialReference) FromURL(url string) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	cURL := C.CString(url)
 	defer C.free(unsafe.Pointer(cURL))
 	return C.OSRImportFromXML(sr.cval, cURL).Err()
@@ -197,6 +288,8 @@ func (sr SpatialReference) FromURL(url string) error {
 
 // Export coordinate system in PCI format
 func (sr SpatialReference) ToPCI() (proj, units string, params []float64, errVal error) {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	var p, u *C.char
 	err := C.OSRExportToPCI(
 		sr.cval, &p, &u, (**C.double)(unsafe.Pointer(&params[0])),
@@ -211,6 +304,8 @@ func (sr SpatialReference) ToPCI() (proj, units string, params []float64, errVal
 
 // Export coordinate system to USGS GCTP projection definition
 func (sr SpatialReference) ToUSGS() (proj, zone int, params []float64, datum int, errVal error) {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	err := C.OSRExportToUSGS(
 		sr.cval,
 		(*C.long)(unsafe.Pointer(&proj)),
@@ -228,6 +323,8 @@ func (sr SpatialReference) ToUSGS() (proj, zone int, params []float64, datum int
 
 // Export coordinate system in XML format
 func (sr SpatialReference) ToXML() (xml string, errVal error) {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	var x *C.char
 	err := C.OSRExportToXML(sr.cval, &x, nil).Err()
 	defer C.free(unsafe.Pointer(x))
@@ -236,6 +333,8 @@ func (sr SpatialReference) ToXML() (xml string, errVal error) {
 
 // Export coordinate system in Mapinfo style CoordSys format
 func (sr SpatialReference) ToMICoordSys() (output string, errVal error) {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	var x *C.char
 	err := C.OSRExportToMICoordSys(sr.cval, &x).Err()
 	defer C.free(unsafe.Pointer(x))
@@ -247,16 +346,21 @@ func (sr SpatialReference) ToMICoordSys() (output string, errVal error) {
 
 // Convert in place to ESRI WKT format
 func (sr SpatialReference) MorphToESRI() error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRMorphToESRI(sr.cval).Err()
 }
 
 // Convert in place from ESRI WKT format
 func (sr SpatialReference) MorphFromESRI() error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRMorphFromESRI(sr.cval).Err()
 }
 
 // Fetch indicated attribute of named node
 func (sr SpatialReference) AttrValue(key string, child int) (value string, ok bool) {
+	defer runtime.KeepAlive(sr.handle)
 	cKey := C.CString(key)
 	defer C.free(unsafe.Pointer(cKey))
 	val := C.OSRGetAttrValue(sr.cval, cKey, C.int(child))
@@ -265,6 +369,8 @@ func (sr SpatialReference) AttrValue(key string, child int) (value string, ok bo
 
 // Set attribute value in spatial reference
 func (sr SpatialReference) SetAttrValue(path, value string) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	cPath := C.CString(path)
 	defer C.free(unsafe.Pointer(cPath))
 	cValue := C.CString(value)
@@ -274,6 +380,8 @@ func (sr SpatialReference) SetAttrValue(path, value string) error {
 
 // Set the angular units for the geographic coordinate system
 func (sr SpatialReference) SetAngularUnits(units string, radians float64) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	cUnits := C.CString(units)
 	defer C.free(unsafe.Pointer(cUnits))
 	return C.OSRSetAngularUnits(sr.cval, cUnits, C.double(radians)).Err()
@@ -281,6 +389,7 @@ func (sr SpatialReference) SetAngularUnits(units string, radians float64) error
 
 // Fetch the angular units for the geographic coordinate system
 func (sr SpatialReference) AngularUnits() (string, float64) {
+	defer runtime.KeepAlive(sr.handle)
 	var x *C.char
 	factor := C.OSRGetAngularUnits(sr.cval, &x)
 	defer C.free(unsafe.Pointer(x))
@@ -289,6 +398,8 @@ func (sr SpatialReference) AngularUnits() (string, float64) {
 
 // Set the linear units for the projection
 func (sr SpatialReference) SetLinearUnits(name string, toMeters float64) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	cName := C.CString(name)
 	defer C.free(unsafe.Pointer(cName))
 	return C.OSRSetLinearUnits(sr.cval, cName, C.double(toMeters)).Err()
@@ -296,6 +407,8 @@ func (sr SpatialReference) SetLinearUnits(name string, toMeters float64) error {
 
 // Set the linear units for the target node
 func (sr SpatialReference) SetTargetLinearUnits(target, units string, toMeters float64) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	cTarget := C.CString(target)
 	defer C.free(unsafe.Pointer(cTarget))
 	cUnits := C.CString(units)
@@ -305,6 +418,8 @@ func (sr SpatialReference) SetTargetLinearUnits(target, units string, toMeters f
 
 // Set the linear units for the target node and update all existing linear parameters
 func (sr SpatialReference) SetLinearUnitsAndUpdateParameters(name string, toMeters float64) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	cName := C.CString(name)
 	defer C.free(unsafe.Pointer(cName))
 	return C.OSRSetLinearUnitsAndUpdateParameters(sr.cval, cName, C.double(toMeters)).Err()
@@ -312,6 +427,7 @@ func (sr SpatialReference) SetLinearUnitsAndUpdateParameters(name string, toMete
 
 // Fetch linear projection units
 func (sr SpatialReference) LinearUnits() (string, float64) {
+	defer runtime.KeepAlive(sr.handle)
 	var x *C.char
 	factor := C.OSRGetLinearUnits(sr.cval, &x)
 	defer C.free(unsafe.Pointer(x))
@@ -320,6 +436,7 @@ func (sr SpatialReference) LinearUnits() (string, float64) {
 
 // Fetch linear units for target
 func (sr SpatialReference) TargetLinearUnits(target string) (string, float64) {
+	defer runtime.KeepAlive(sr.handle)
 	cTarget := C.CString(target)
 	defer C.free(unsafe.Pointer(cTarget))
 	var x *C.char
@@ -330,6 +447,7 @@ func (sr SpatialReference) TargetLinearUnits(target string) (string, float64) {
 
 // Fetch prime meridian information
 func (sr SpatialReference) PrimeMeridian() (string, float64) {
+	defer runtime.KeepAlive(sr.handle)
 	var x *C.char
 	offset := C.OSRGetPrimeMeridian(sr.cval, &x)
 	defer C.free(unsafe.Pointer(x))
@@ -338,60 +456,74 @@ func (sr SpatialReference) PrimeMeridian() (string, float64) {
 
 // Return true if geographic coordinate system
 func (sr SpatialReference) IsGeographic() bool {
+	defer runtime.KeepAlive(sr.handle)
 	val := C.OSRIsGeographic(sr.cval)
 	return val != 0
 }
 
 // Return true if local coordinate system
 func (sr SpatialReference) IsLocal() bool {
+	defer runtime.KeepAlive(sr.handle)
 	val := C.OSRIsLocal(sr.cval)
 	return val != 0
 }
 
 // Return true if projected coordinate system
 func (sr SpatialReference) IsProjected() bool {
+	defer runtime.KeepAlive(sr.handle)
 	val := C.OSRIsProjected(sr.cval)
 	return val != 0
 }
 
 // Return true if compound coordinate system
 func (sr SpatialReference) IsCompound() bool {
+	defer runtime.KeepAlive(sr.handle)
 	val := C.OSRIsCompound(sr.cval)
 	return val != 0
 }
 
 // Return true if geocentric coordinate system
 func (sr SpatialReference) IsGeocentric() bool {
+	defer runtime.KeepAlive(sr.handle)
 	val := C.OSRIsGeocentric(sr.cval)
 	return val != 0
 }
 
 // Return true if vertical coordinate system
 func (sr SpatialReference) IsVertical() bool {
+	defer runtime.KeepAlive(sr.handle)
 	val := C.OSRIsVertical(sr.cval)
 	return val != 0
 }
 
 // Return true if the geographic coordinate systems match
 func (sr SpatialReference) IsSameGeographicCS(other SpatialReference) bool {
+	defer runtime.KeepAlive(sr.handle)
+	defer runtime.KeepAlive(other.handle)
 	val := C.OSRIsSameGeogCS(sr.cval, other.cval)
 	return val != 0
 }
 
 // Return true if the vertical coordinate systems match
 func (sr SpatialReference) IsSameVerticalCS(other SpatialReference) bool {
+	defer runtime.KeepAlive(sr.handle)
+	defer runtime.KeepAlive(other.handle)
 	val := C.OSRIsSameVertCS(sr.cval, other.cval)
 	return val != 0
 }
 
 // Return true if the coordinate systems describe the same system
 func (sr SpatialReference) IsSame(other SpatialReference) bool {
+	defer runtime.KeepAlive(sr.handle)
+	defer runtime.KeepAlive(other.handle)
 	val := C.OSRIsSame(sr.cval, other.cval)
 	return val != 0
 }
 
 // Set the user visible local CS name
 func (sr SpatialReference) SetLocalCS(name string) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	cName := C.CString(name)
 	defer C.free(unsafe.Pointer(cName))
 	return C.OSRSetLocalCS(sr.cval, cName).Err()
@@ -399,6 +531,8 @@ func (sr SpatialReference) SetLocalCS(name string) error {
 
 // Set the user visible projected CS name
 func (sr SpatialReference) SetProjectedCS(name string) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	cName := C.CString(name)
 	defer C.free(unsafe.Pointer(cName))
 	return C.OSRSetProjCS(sr.cval, cName).Err()
@@ -406,6 +540,8 @@ func (sr SpatialReference) SetProjectedCS(name string) error {
 
 // Set the user visible geographic CS name
 func (sr SpatialReference) SetGeocentricCS(name string) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	cName := C.CString(name)
 	defer C.free(unsafe.Pointer(cName))
 	return C.OSRSetGeocCS(sr.cval, cName).Err()
@@ -413,6 +549,8 @@ func (sr SpatialReference) SetGeocentricCS(name string) error {
 
 // Set geographic CS based on well known name
 func (sr SpatialReference) SetWellKnownGeographicCS(name string) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	cName := C.CString(name)
 	defer C.free(unsafe.Pointer(cName))
 	return C.OSRSetWellKnownGeogCS(sr.cval, cName).Err()
@@ -420,6 +558,8 @@ func (sr SpatialReference) SetWellKnownGeographicCS(name string) error {
 
 // Set spatial reference from various text formats
 func (sr SpatialReference) SetFromUserInput(name string) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	cName := C.CString(name)
 	defer C.free(unsafe.Pointer(cName))
 	return C.OSRSetFromUserInput(sr.cval, cName).Err()
@@ -427,11 +567,16 @@ func (sr SpatialReference) SetFromUserInput(name string) error {
 
 // Copy geographic CS from another spatial reference
 func (sr SpatialReference) CopyGeographicCSFrom(other SpatialReference) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer runtime.KeepAlive(other.handle)
+	defer pinErrorState()()
 	return C.OSRCopyGeogCSFrom(sr.cval, other.cval).Err()
 }
 
 // Set the Bursa-Wolf conversion to WGS84
 func (sr SpatialReference) SetTOWGS84(dx, dy, dz, ex, ey, ez, ppm float64) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRSetTOWGS84(
 		sr.cval,
 		C.double(dx),
@@ -446,6 +591,8 @@ func (sr SpatialReference) SetTOWGS84(dx, dy, dz, ex, ey, ez, ppm float64) error
 
 // Fetch the TOWGS84 parameters if available
 func (sr SpatialReference) TOWGS84() (coeff [7]float64, err error) {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	err = C.OSRGetTOWGS84(sr.cval, (*C.double)(unsafe.Pointer(&coeff[0])), 7).Err()
 	return
 }
@@ -455,6 +602,10 @@ func (sr SpatialReference) SetCompoundCS(
 	name string,
 	horizontal, vertical SpatialReference,
 ) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer runtime.KeepAlive(horizontal.handle)
+	defer runtime.KeepAlive(vertical.handle)
+	defer pinErrorState()()
 	cName := C.CString(name)
 	defer C.free(unsafe.Pointer(cName))
 	return C.OSRSetCompoundCS(sr.cval, cName, horizontal.cval, vertical.cval).Err()
@@ -469,6 +620,8 @@ func (sr SpatialReference) SetGeographicCS(
 	angularUnits string,
 	toRadians float64,
 ) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	cGeogName := C.CString(geogName)
 	defer C.free(unsafe.Pointer(cGeogName))
 	cDatumName := C.CString(datumName)
@@ -495,6 +648,8 @@ func (sr SpatialReference) SetGeographicCS(
 
 // Set up the vertical coordinate system
 func (sr SpatialReference) SetVerticalCS(csName, datumName string, datumType int) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	cCSName := C.CString(csName)
 	defer C.free(unsafe.Pointer(cCSName))
 	cDatumName := C.CString(datumName)
@@ -504,6 +659,8 @@ func (sr SpatialReference) SetVerticalCS(csName, datumName string, datumType int
 
 // Get spheroid semi-major axis
 func (sr SpatialReference) SemiMajorAxis() (float64, error) {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	var cErr C.OGRErr
 	axis := C.OSRGetSemiMajor(sr.cval, &cErr)
 	return float64(axis), cErr.Err()
@@ -511,6 +668,8 @@ func (sr SpatialReference) SemiMajorAxis() (float64, error) {
 
 // Get spheroid semi-minor axis
 func (sr SpatialReference) SemiMinorAxis() (float64, error) {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	var cErr C.OGRErr
 	axis := C.OSRGetSemiMinor(sr.cval, &cErr)
 	return float64(axis), cErr.Err()
@@ -518,6 +677,8 @@ func (sr SpatialReference) SemiMinorAxis() (float64, error) {
 
 // Get spheroid inverse flattening axis
 func (sr SpatialReference) InverseFlattening() (float64, error) {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	var cErr C.OGRErr
 	flat := C.OSRGetInvFlattening(sr.cval, &cErr)
 	return float64(flat), cErr.Err()
@@ -525,6 +686,8 @@ func (sr SpatialReference) InverseFlattening() (float64, error) {
 
 // Sets the authority for a node
 func (sr SpatialReference) SetAuthority(target, authority string, code int) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	cTarget := C.CString(target)
 	defer C.free(unsafe.Pointer(cTarget))
 	cAuthority := C.CString(authority)
@@ -534,6 +697,7 @@ func (sr SpatialReference) SetAuthority(target, authority string, code int) erro
 
 // Get the authority code for a node
 func (sr SpatialReference) AuthorityCode(target string) string {
+	defer runtime.KeepAlive(sr.handle)
 	cTarget := C.CString(target)
 	defer C.free(unsafe.Pointer(cTarget))
 	code := C.OSRGetAuthorityCode(sr.cval, cTarget)
@@ -542,6 +706,7 @@ func (sr SpatialReference) AuthorityCode(target string) string {
 
 // Get the authority name for a node
 func (sr SpatialReference) AuthorityName(target string) string {
+	defer runtime.KeepAlive(sr.handle)
 	cTarget := C.CString(target)
 	defer C.free(unsafe.Pointer(cTarget))
 	code := C.OSRGetAuthorityName(sr.cval, cTarget)
@@ -550,6 +715,8 @@ func (sr SpatialReference) AuthorityName(target string) string {
 
 // Set a projection by name
 func (sr SpatialReference) SetProjectionByName(name string) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	cName := C.CString(name)
 	defer C.free(unsafe.Pointer(cName))
 	return C.OSRSetProjection(sr.cval, cName).Err()
@@ -557,6 +724,8 @@ func (sr SpatialReference) SetProjectionByName(name string) error {
 
 // Set a projection parameter value
 func (sr SpatialReference) SetProjectionParameter(name string, value float64) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	cName := C.CString(name)
 	defer C.free(unsafe.Pointer(cName))
 	return C.OSRSetProjParm(sr.cval, cName, C.double(value)).Err()
@@ -564,6 +733,8 @@ func (sr SpatialReference) SetProjectionParameter(name string, value float64) er
 
 // Fetch a projection parameter value
 func (sr SpatialReference) ProjectionParameter(name string, defaultValue float64) (float64, error) {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	cName := C.CString(name)
 	defer C.free(unsafe.Pointer(cName))
 	var cErr C.OGRErr
@@ -573,6 +744,8 @@ func (sr SpatialReference) ProjectionParameter(name string, defaultValue float64
 
 // Set a projection parameter with a normalized value
 func (sr SpatialReference) SetNormalizedProjectionParameter(name string, value float64) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	cName := C.CString(name)
 	defer C.free(unsafe.Pointer(cName))
 	return C.OSRSetNormProjParm(sr.cval, cName, C.double(value)).Err()
@@ -582,6 +755,8 @@ func (sr SpatialReference) SetNormalizedProjectionParameter(name string, value f
 func (sr SpatialReference) NormalizedProjectionParameter(
 	name string, defaultValue float64,
 ) (float64, error) {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	cName := C.CString(name)
 	defer C.free(unsafe.Pointer(cName))
 	var cErr C.OGRErr
@@ -591,11 +766,14 @@ func (sr SpatialReference) NormalizedProjectionParameter(
 
 // Set UTM projection definition
 func (sr SpatialReference) SetUTM(zone int, north bool) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRSetUTM(sr.cval, C.int(zone), BoolToCInt(north)).Err()
 }
 
 // Get UTM zone information
 func (sr SpatialReference) UTMZone() (zone int, north bool) {
+	defer runtime.KeepAlive(sr.handle)
 	var northInt C.int
 	cZone := C.OSRGetUTMZone(sr.cval, &northInt)
 	return int(cZone), northInt != 0
@@ -603,6 +781,8 @@ func (sr SpatialReference) UTMZone() (zone int, north bool) {
 
 // Set State Plane projection definition
 func (sr SpatialReference) SetStatePlane(zone int, nad83 bool) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRSetStatePlane(sr.cval, C.int(zone), BoolToCInt(nad83)).Err()
 }
 
@@ -613,6 +793,8 @@ func (sr SpatialReference) SetStatePlaneWithUnits(
 	unitName string,
 	factor float64,
 ) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	cUnitName := C.CString(unitName)
 	defer C.free(unsafe.Pointer(cUnitName))
 	return C.OSRSetStatePlaneWithUnits(
@@ -626,11 +808,14 @@ func (sr SpatialReference) SetStatePlaneWithUnits(
 
 // Set EPSG authority info if possible
 func (sr SpatialReference) AutoIdentifyEPSG() error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRAutoIdentifyEPSG(sr.cval).Err()
 }
 
 // Return true if EPSG feels this coordinate system should be treated as having lat/long coordinate ordering
 func (sr SpatialReference) EPSGTreatsAsLatLong() bool {
+	defer runtime.KeepAlive(sr.handle)
 	val := C.OSREPSGTreatsAsLatLong(sr.cval)
 	return val != 0
 }
@@ -642,6 +827,8 @@ func (sr SpatialReference) EPSGTreatsAsLatLong() bool {
 func (sr SpatialReference) SetACEA(
 	stdp1, stdp2, centerLat, centerLong, falseEasting, falseNorthing float64,
 ) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRSetACEA(
 		sr.cval,
 		C.double(stdp1),
@@ -655,6 +842,8 @@ func (sr SpatialReference) SetACEA(
 
 // Set to Azimuthal Equidistant
 func (sr SpatialReference) SetAE(centerLat, centerLong, falseEasting, falseNorthing float64) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRSetAE(
 		sr.cval,
 		C.double(centerLat),
@@ -666,6 +855,8 @@ func (sr SpatialReference) SetAE(centerLat, centerLong, falseEasting, falseNorth
 
 // Set to Bonne
 func (sr SpatialReference) SetBonne(standardParallel, centralMeridian, falseEasting, falseNorthing float64) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRSetBonne(
 		sr.cval,
 		C.double(standardParallel),
@@ -677,6 +868,8 @@ func (sr SpatialReference) SetBonne(standardParallel, centralMeridian, falseEast
 
 // Set to Cylindrical Equal Area
 func (sr SpatialReference) SetCEA(stdp1, centralMeridian, falseEasting, falseNorthing float64) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRSetCEA(
 		sr.cval,
 		C.double(stdp1),
@@ -688,6 +881,8 @@ func (sr SpatialReference) SetCEA(stdp1, centralMeridian, falseEasting, falseNor
 
 // Set to Cassini-Soldner
 func (sr SpatialReference) SetCS(centerLat, centerLong, falseEasting, falseNorthing float64) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRSetCS(
 		sr.cval,
 		C.double(centerLat),
@@ -701,6 +896,8 @@ func (sr SpatialReference) SetCS(centerLat, centerLong, falseEasting, falseNorth
 func (sr SpatialReference) SetEC(
 	stdp1, stdp2, centerLat, centerLong, falseEasting, falseNorthing float64,
 ) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRSetEC(
 		sr.cval,
 		C.double(stdp1),
@@ -714,6 +911,8 @@ func (sr SpatialReference) SetEC(
 
 // Set to Eckert I-VI
 func (sr SpatialReference) SetEckert(variation int, centralMeridian, falseEasting, falseNorthing float64) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRSetEckert(
 		sr.cval,
 		C.int(variation),
@@ -727,6 +926,8 @@ func (sr SpatialReference) SetEckert(variation int, centralMeridian, falseEastin
 func (sr SpatialReference) SetEquirectangular(
 	centerLat, centerLong, falseEasting, falseNorthing float64,
 ) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRSetEquirectangular(
 		sr.cval,
 		C.double(centerLat),
@@ -740,6 +941,8 @@ func (sr SpatialReference) SetEquirectangular(
 func (sr SpatialReference) SetEquirectangularGeneralized(
 	centerLat, centerLong, psuedoStdParallel, falseEasting, falseNorthing float64,
 ) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRSetEquirectangular2(
 		sr.cval,
 		C.double(centerLat),
@@ -752,6 +955,8 @@ func (sr SpatialReference) SetEquirectangularGeneralized(
 
 // Set to Gall Stereographic
 func (sr SpatialReference) SetGS(centralMeridian, falseEasting, falseNorthing float64) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRSetGS(
 		sr.cval,
 		C.double(centralMeridian),
@@ -762,6 +967,8 @@ func (sr SpatialReference) SetGS(centralMeridian, falseEasting, falseNorthing fl
 
 // Set to Goode Homolosine
 func (sr SpatialReference) SetGH(centralMeridian, falseEasting, falseNorthing float64) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRSetGH(
 		sr.cval,
 		C.double(centralMeridian),
@@ -772,6 +979,8 @@ func (sr SpatialReference) SetGH(centralMeridian, falseEasting, falseNorthing fl
 
 // Set to Interrupted Goode Homolosine
 func (sr SpatialReference) SetIGH() error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRSetIGH(sr.cval).Err()
 }
 
@@ -779,6 +988,8 @@ func (sr SpatialReference) SetIGH() error {
 func (sr SpatialReference) SetGEOS(
 	centralMeridian, satelliteHeight, falseEasting, falseNorthing float64,
 ) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRSetGEOS(
 		sr.cval,
 		C.double(centralMeridian),
@@ -792,6 +1003,8 @@ func (sr SpatialReference) SetGEOS(
 func (sr SpatialReference) SetGSTM(
 	centerLat, centerLong, scale, falseEasting, falseNorthing float64,
 ) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRSetGaussSchreiberTMercator(
 		sr.cval,
 		C.double(centerLat),
@@ -806,6 +1019,8 @@ func (sr SpatialReference) SetGSTM(
 func (sr SpatialReference) SetGnomonic(
 	centerLat, centerLong, falseEasting, falseNorthing float64,
 ) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRSetGnomonic(
 		sr.cval,
 		C.double(centerLat),
@@ -819,6 +1034,8 @@ func (sr SpatialReference) SetGnomonic(
 func (sr SpatialReference) SetHOM(
 	centerLat, centerLong, azimuth, rectToSkew, scale, falseEasting, falseNorthing float64,
 ) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRSetHOM(
 		sr.cval,
 		C.double(centerLat),
@@ -835,6 +1052,8 @@ func (sr SpatialReference) SetHOM(
 func (sr SpatialReference) SetHOM2PNO(
 	centerLat, lat1, long1, lat2, long2, scale, falseEasting, falseNorthing float64,
 ) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRSetHOM2PNO(
 		sr.cval,
 		C.double(centerLat),
@@ -852,6 +1071,8 @@ func (sr SpatialReference) SetHOM2PNO(
 func (sr SpatialReference) SetIWMPolyconic(
 	lat1, lat2, centerLong, falseEasting, falseNorthing float64,
 ) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRSetIWMPolyconic(
 		sr.cval,
 		C.double(lat1),
@@ -866,6 +1087,8 @@ func (sr SpatialReference) SetIWMPolyconic(
 func (sr SpatialReference) SetKrovak(
 	centerLat, centerLong, azimuth, psuedoStdParallel, scale, falseEasting, falseNorthing float64,
 ) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRSetKrovak(
 		sr.cval,
 		C.double(centerLat),
@@ -882,6 +1105,8 @@ func (sr SpatialReference) SetKrovak(
 func (sr SpatialReference) SetLAEA(
 	centerLat, centerLong, falseEasting, falseNorthing float64,
 ) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRSetLAEA(
 		sr.cval,
 		C.double(centerLat),
@@ -895,6 +1120,8 @@ func (sr SpatialReference) SetLAEA(
 func (sr SpatialReference) SetLCC(
 	stdp1, stdp2, centerLat, centerLong, falseEasting, falseNorthing float64,
 ) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRSetLCC(
 		sr.cval,
 		C.double(stdp1),
@@ -910,6 +1137,8 @@ func (sr SpatialReference) SetLCC(
 func (sr SpatialReference) SetLCC1SP(
 	centerLat, centerLong, scale, falseEasting, falseNorthing float64,
 ) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRSetLCC1SP(
 		sr.cval,
 		C.double(centerLat),
@@ -924,6 +1153,8 @@ func (sr SpatialReference) SetLCC1SP(
 func (sr SpatialReference) SetLCCB(
 	stdp1, stdp2, centerLat, centerLong, falseEasting, falseNorthing float64,
 ) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRSetLCCB(
 		sr.cval,
 		C.double(stdp1),
@@ -939,6 +1170,8 @@ func (sr SpatialReference) SetLCCB(
 func (sr SpatialReference) SetMC(
 	centerLat, centerLong, falseEasting, falseNorthing float64,
 ) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRSetMC(
 		sr.cval,
 		C.double(centerLat),
@@ -952,6 +1185,8 @@ func (sr SpatialReference) SetMC(
 func (sr SpatialReference) SetMercator(
 	centerLat, centerLong, scale, falseEasting, falseNorthing float64,
 ) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRSetMercator(
 		sr.cval,
 		C.double(centerLat),
@@ -966,6 +1201,8 @@ func (sr SpatialReference) SetMercator(
 func (sr SpatialReference) SetMollweide(
 	centralMeridian, falseEasting, falseNorthing float64,
 ) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRSetMollweide(
 		sr.cval,
 		C.double(centralMeridian),
@@ -978,6 +1215,8 @@ func (sr SpatialReference) SetMollweide(
 func (sr SpatialReference) SetNZMG(
 	centerLat, centerLong, falseEasting, falseNorthing float64,
 ) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRSetNZMG(
 		sr.cval,
 		C.double(centerLat),
@@ -991,6 +1230,8 @@ func (sr SpatialReference) SetNZMG(
 func (sr SpatialReference) SetOS(
 	originLat, meridian, scale, falseEasting, falseNorthing float64,
 ) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRSetOS(
 		sr.cval,
 		C.double(originLat),
@@ -1005,6 +1246,8 @@ func (sr SpatialReference) SetOS(
 func (sr SpatialReference) SetOrthographic(
 	centerLat, centerLong, falseEasting, falseNorthing float64,
 ) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRSetOrthographic(
 		sr.cval,
 		C.double(centerLat),
@@ -1018,6 +1261,8 @@ func (sr SpatialReference) SetOrthographic(
 func (sr SpatialReference) SetPolyconic(
 	centerLat, centerLong, falseEasting, falseNorthing float64,
 ) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRSetPolyconic(
 		sr.cval,
 		C.double(centerLat),
@@ -1031,6 +1276,8 @@ func (sr SpatialReference) SetPolyconic(
 func (sr SpatialReference) SetPS(
 	centerLat, centerLong, scale, falseEasting, falseNorthing float64,
 ) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRSetPS(
 		sr.cval,
 		C.double(centerLat),
@@ -1045,6 +1292,8 @@ func (sr SpatialReference) SetPS(
 func (sr SpatialReference) SetRobinson(
 	centerLong, falseEasting, falseNorthing float64,
 ) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRSetRobinson(
 		sr.cval,
 		C.double(centerLong),
@@ -1057,6 +1306,8 @@ func (sr SpatialReference) SetRobinson(
 func (sr SpatialReference) SetSinusoidal(
 	centerLong, falseEasting, falseNorthing float64,
 ) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRSetSinusoidal(
 		sr.cval,
 		C.double(centerLong),
@@ -1069,6 +1320,8 @@ func (sr SpatialReference) SetSinusoidal(
 func (sr SpatialReference) SetStereographic(
 	centerLat, centerLong, scale, falseEasting, falseNorthing float64,
 ) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRSetStereographic(
 		sr.cval,
 		C.double(centerLat),
@@ -1083,6 +1336,8 @@ func (sr SpatialReference) SetStereographic(
 func (sr SpatialReference) SetSOC(
 	latitudeOfOrigin, centralMeridian, falseEasting, falseNorthing float64,
 ) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRSetSOC(
 		sr.cval,
 		C.double(latitudeOfOrigin),
@@ -1096,6 +1351,8 @@ func (sr SpatialReference) SetSOC(
 func (sr SpatialReference) SetTM(
 	centerLat, centerLong, scale, falseEasting, falseNorthing float64,
 ) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRSetTM(
 		sr.cval,
 		C.double(centerLat),
@@ -1110,6 +1367,8 @@ func (sr SpatialReference) SetTM(
 func (sr SpatialReference) SetTMVariant(
 	variantName string, centerLat, centerLong, scale, falseEasting, falseNorthing float64,
 ) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	cName := C.CString(variantName)
 	defer C.free(unsafe.Pointer(cName))
 	return C.OSRSetTMVariant(
@@ -1127,6 +1386,8 @@ func (sr SpatialReference) SetTMVariant(
 func (sr SpatialReference) SetTMG(
 	centerLat, centerLong, falseEasting, falseNorthing float64,
 ) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRSetTMG(
 		sr.cval,
 		C.double(centerLat),
@@ -1140,6 +1401,8 @@ func (sr SpatialReference) SetTMG(
 func (sr SpatialReference) SetTMSO(
 	centerLat, centerLong, scale, falseEasting, falseNorthing float64,
 ) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRSetTMSO(
 		sr.cval,
 		C.double(centerLat),
@@ -1154,6 +1417,8 @@ func (sr SpatialReference) SetTMSO(
 func (sr SpatialReference) SetVDG(
 	centerLong, falseEasting, falseNorthing float64,
 ) error {
+	defer runtime.KeepAlive(sr.handle)
+	defer pinErrorState()()
 	return C.OSRSetVDG(
 		sr.cval,
 		C.double(centerLong),
@@ -1173,6 +1438,13 @@ func CleanupSR() {
 
 type CoordinateTransform struct {
 	cval C.OGRCoordinateTransformationH
+	*handle
+}
+
+// Return true if this coordinate transform wraps a NULL handle or has been
+// destroyed
+func (ct CoordinateTransform) IsNil() bool {
+	return ct.cval == nil || ct.isClosed()
 }
 
 // Create a new CoordinateTransform
@@ -1180,16 +1452,27 @@ func CreateCoordinateTransform(
 	source SpatialReference,
 	dest SpatialReference,
 ) CoordinateTransform {
+	defer runtime.KeepAlive(source.handle)
+	defer runtime.KeepAlive(dest.handle)
 	ct := C.OCTNewCoordinateTransformation(source.cval, dest.cval)
-	return CoordinateTransform{ct}
+	return CoordinateTransform{ct, ownHandle("CoordinateTransform", ct, func(ct C.OGRCoordinateTransformationH) error {
+		C.OCTDestroyCoordinateTransformation(ct)
+		return nil
+	})}
 }
 
 // Destroy CoordinateTransform
 func (ct CoordinateTransform) Destroy() {
-	C.OCTDestroyCoordinateTransformation(ct.cval)
+	ct.close()
+}
+
+// Destroy CoordinateTransform. Closing more than once is a no-op.
+func (ct CoordinateTransform) Close() error {
+	return ct.close()
 }
 
 func (ct CoordinateTransform) Transform(numPoints int, xPoints []float64, yPoints []float64, zPoints []float64) bool {
+	defer runtime.KeepAlive(ct.handle)
 	val := C.OCTTransform(ct.cval, C.int(numPoints), (*C.double)(unsafe.Pointer(&xPoints[0])), (*C.double)(unsafe.Pointer(&yPoints[0])), (*C.double)(unsafe.Pointer(&zPoints[0])))
 	return int(val) != 0
 }
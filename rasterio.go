@@ -0,0 +1,223 @@
+package gdal
+
+/*
+#include "go_gdal.h"
+*/
+import "C"
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"unsafe"
+)
+
+/* --------------------------------------------- */
+/* Typed raster IO                               */
+/* --------------------------------------------- */
+
+// Numeric is the set of Go element types that map directly onto a GDAL
+// pixel data type.
+type Numeric interface {
+	~uint8 | ~int16 | ~uint16 | ~int32 | ~uint32 |
+		~float32 | ~float64 | ~complex64 | ~complex128
+}
+
+// DataTypeOf returns the GDAL data type matching the element type T
+func DataTypeOf[T Numeric]() DataType {
+	var zero T
+	return kindDataType(reflect.TypeOf(zero).Kind())
+}
+
+// kindDataType returns Unknown for int8, since GDAL reads and writes Byte
+// pixels as unsigned
+func kindDataType(kind reflect.Kind) DataType {
+	switch kind {
+	case reflect.Uint8:
+		return Byte
+	case reflect.Int16:
+		return Int16
+	case reflect.Uint16:
+		return UInt16
+	case reflect.Int32:
+		return Int32
+	case reflect.Uint32:
+		return UInt32
+	case reflect.Float32:
+		return Float32
+	case reflect.Float64:
+		return Float64
+	case reflect.Complex64:
+		return CFloat32
+	case reflect.Complex128:
+		return CFloat64
+	}
+	return Unknown
+}
+
+// bufferOf returns a pointer to the first element of a numeric slice, the
+// GDAL data type of its elements and its length.
+func bufferOf(buffer interface{}) (unsafe.Pointer, DataType, int, error) {
+	v := reflect.ValueOf(buffer)
+	if v.Kind() != reflect.Slice {
+		return nil, Unknown, 0, fmt.Errorf("buffer is not a valid data type (must be a valid numeric slice)")
+	}
+	dataType := kindDataType(v.Type().Elem().Kind())
+	if dataType == Unknown {
+		return nil, Unknown, 0, fmt.Errorf("buffer element type %s has no matching GDAL data type", v.Type().Elem())
+	}
+	if v.Len() == 0 {
+		return nil, dataType, 0, nil
+	}
+	return v.UnsafePointer(), dataType, v.Len(), nil
+}
+
+// ReadRegion reads a window of the band into buf, letting GDAL convert from
+// the band's data type to T and resample to bufXSize x bufYSize.
+func ReadRegion[T Numeric](
+	rasterBand RasterBand,
+	xOff, yOff, xSize, ySize int,
+	buf []T,
+	bufXSize, bufYSize int,
+) error {
+	return regionIO(rasterBand, Read, xOff, yOff, xSize, ySize, buf, bufXSize, bufYSize)
+}
+
+// WriteRegion writes buf to a window of the band, letting GDAL convert from
+// T to the band's data type and resample from bufXSize x bufYSize.
+func WriteRegion[T Numeric](
+	rasterBand RasterBand,
+	xOff, yOff, xSize, ySize int,
+	buf []T,
+	bufXSize, bufYSize int,
+) error {
+	return regionIO(rasterBand, Write, xOff, yOff, xSize, ySize, buf, bufXSize, bufYSize)
+}
+
+func regionIO[T Numeric](
+	rasterBand RasterBand,
+	rwFlag RWFlag,
+	xOff, yOff, xSize, ySize int,
+	buf []T,
+	bufXSize, bufYSize int,
+) error {
+	defer runtime.KeepAlive(rasterBand.dataset)
+	err := checkWindow(xOff, yOff, xSize, ySize, rasterBand.XSize(), rasterBand.YSize())
+	if err != nil {
+		return err
+	}
+	if bufXSize <= 0 || bufYSize <= 0 {
+		return fmt.Errorf("invalid buffer size %dx%d", bufXSize, bufYSize)
+	}
+	if need := bufXSize * bufYSize; len(buf) < need {
+		return fmt.Errorf("buffer holds %d elements, %dx%d requires %d", len(buf), bufXSize, bufYSize, need)
+	}
+
+	defer pinErrorState()()
+	return C.GDALRasterIO(
+		rasterBand.cval,
+		C.GDALRWFlag(rwFlag),
+		C.int(xOff), C.int(yOff), C.int(xSize), C.int(ySize),
+		unsafe.Pointer(&buf[0]),
+		C.int(bufXSize), C.int(bufYSize),
+		C.GDALDataType(DataTypeOf[T]()),
+		0, 0,
+	).Err()
+}
+
+// ReadBlockSlice reads one block of the band into buf. T must match the
+// band's data type and buf must hold a full block.
+func ReadBlockSlice[T Numeric](rasterBand RasterBand, xBlock, yBlock int, buf []T) error {
+	if err := checkBlockSlice(rasterBand, buf); err != nil {
+		return err
+	}
+	return rasterBand.ReadBlock(xBlock, yBlock, unsafe.Pointer(&buf[0]))
+}
+
+// WriteBlockSlice writes one block of the band from buf. T must match the
+// band's data type and buf must hold a full block.
+func WriteBlockSlice[T Numeric](rasterBand RasterBand, xBlock, yBlock int, buf []T) error {
+	if err := checkBlockSlice(rasterBand, buf); err != nil {
+		return err
+	}
+	return rasterBand.WriteBlock(xBlock, yBlock, unsafe.Pointer(&buf[0]))
+}
+
+func checkBlockSlice[T Numeric](rasterBand RasterBand, buf []T) error {
+	bandType := rasterBand.RasterDataType()
+	if dataType := DataTypeOf[T](); dataType != bandType {
+		return fmt.Errorf(
+			"buffer data type %s does not match band data type %s",
+			dataType.Name(), bandType.Name(),
+		)
+	}
+	blockX, blockY := rasterBand.BlockSize()
+	if need := blockX * blockY; len(buf) < need {
+		return fmt.Errorf("buffer holds %d elements, %dx%d block requires %d", len(buf), blockX, blockY, need)
+	}
+	return nil
+}
+
+/* --------------------------------------------- */
+/* Argument validation                           */
+/* --------------------------------------------- */
+
+// checkWindow reports whether a pixel window lies inside a raster.
+func checkWindow(xOff, yOff, xSize, ySize, rasterXSize, rasterYSize int) error {
+	if xSize <= 0 || ySize <= 0 {
+		return fmt.Errorf("invalid window size %dx%d", xSize, ySize)
+	}
+	if xOff < 0 || yOff < 0 || xOff+xSize > rasterXSize || yOff+ySize > rasterYSize {
+		return fmt.Errorf(
+			"window %dx%d at (%d, %d) is outside the %dx%d raster",
+			xSize, ySize, xOff, yOff, rasterXSize, rasterYSize,
+		)
+	}
+	return nil
+}
+
+// checkBufferSize reports whether a buffer of length elements of elemSize
+// bytes is large enough for a RasterIO request. Zero spacings take the
+// GDAL defaults.
+func checkBufferSize(
+	length, elemSize int,
+	bufXSize, bufYSize, bandCount int,
+	pixelSpace, lineSpace, bandSpace int,
+) error {
+	if length == 0 {
+		return fmt.Errorf("buffer is empty")
+	}
+	if bufXSize <= 0 || bufYSize <= 0 {
+		return fmt.Errorf("invalid buffer size %dx%d", bufXSize, bufYSize)
+	}
+	if pixelSpace < 0 || lineSpace < 0 || bandSpace < 0 {
+		return fmt.Errorf("negative pixel, line or band spacing")
+	}
+	if pixelSpace == 0 {
+		pixelSpace = elemSize
+	}
+	if lineSpace == 0 {
+		lineSpace = pixelSpace * bufXSize
+	}
+	if bandSpace == 0 {
+		bandSpace = lineSpace * bufYSize
+	}
+	need := (bufXSize-1)*pixelSpace + (bufYSize-1)*lineSpace + (bandCount-1)*bandSpace + elemSize
+	if have := length * elemSize; have < need {
+		return fmt.Errorf("buffer holds %d bytes, request needs %d", have, need)
+	}
+	return nil
+}
+
+// checkBlock reports whether a block index lies inside the band.
+func (rasterBand RasterBand) checkBlock(xBlock, yBlock int) error {
+	blockX, blockY := rasterBand.BlockSize()
+	if blockX <= 0 || blockY <= 0 {
+		return fmt.Errorf("invalid block size %dx%d", blockX, blockY)
+	}
+	countX := (rasterBand.XSize() + blockX - 1) / blockX
+	countY := (rasterBand.YSize() + blockY - 1) / blockY
+	if xBlock < 0 || yBlock < 0 || xBlock >= countX || yBlock >= countY {
+		return fmt.Errorf("block (%d, %d) out of range %dx%d", xBlock, yBlock, countX, countY)
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+package gdal
+
+import (
+	"fmt"
+	"math"
+)
+
+/* --------------------------------------------- */
+/* Band statistics                               */
+/* --------------------------------------------- */
+
+// Statistics summarizes the valid pixels of a band. Min, Max, Mean and
+// StdDev are NaN when no pixel is valid.
+type Statistics struct {
+	Min, Max     float64
+	Mean, StdDev float64
+	// Pixels included in the statistics
+	ValidCount int
+	// Pixels excluded by the nodata value, the mask band or NaN
+	NoDataCount int
+}
+
+// Return the fraction of pixels that are valid, or 0 if there are none
+func (stats Statistics) ValidFraction() float64 {
+	total := stats.ValidCount + stats.NoDataCount
+	if total == 0 {
+		return 0
+	}
+	return float64(stats.ValidCount) / float64(total)
+}
+
+// StatisticsOptions selects the pixels RasterBand.Statistics reads
+type StatisticsOptions struct {
+	// Compute from at most approxPixels pixels, read from overviews when
+	// the band has them. Counts are then counts of the sampled pixels.
+	ApproxOK bool
+	// Pixel window to read. The zero value selects the whole band, or the
+	// part of it covered by Geometry.
+	Window Window
+	// Only count pixels whose center lies inside this geometry, given in
+	// the georeferenced coordinates of the dataset
+	Geometry Geometry
+	// With Geometry, count every pixel the geometry touches
+	AllTouched bool
+}
+
+// Number of pixels read at most when ApproxOK is set
+const approxPixels = 1 << 20
+
+// Statistics computes the statistics of the band, leaving out pixels
+// flagged as invalid by the nodata value or the mask band
+func (rasterBand RasterBand) Statistics(opts StatisticsOptions) (Statistics, error) {
+	window := opts.Window
+	hasGeometry := !opts.Geometry.IsNil()
+	if window == (Window{}) {
+		window = Window{XSize: rasterBand.XSize(), YSize: rasterBand.YSize()}
+		if hasGeometry {
+			var err error
+			if window, err = rasterBand.geometryWindow(opts.Geometry); err != nil {
+				return Statistics{}, err
+			}
+		}
+	}
+	err := checkWindow(
+		window.XOff, window.YOff, window.XSize, window.YSize,
+		rasterBand.XSize(), rasterBand.YSize(),
+	)
+	if err != nil {
+		return Statistics{}, err
+	}
+
+	bufX, bufY := window.XSize, window.YSize
+	if opts.ApproxOK && bufX*bufY > approxPixels {
+		scale := math.Sqrt(float64(approxPixels) / float64(bufX*bufY))
+		bufX = maxInt(int(float64(bufX)*scale), 1)
+		bufY = maxInt(int(float64(bufY)*scale), 1)
+	}
+
+	var inside []uint8
+	if hasGeometry {
+		if inside, err = rasterBand.rasterizeWindow(opts.Geometry, opts.AllTouched, window, bufX, bufY); err != nil {
+			return Statistics{}, err
+		}
+	}
+
+	// Read full width strips one block high, or the whole sample at once
+	sampled := bufX != window.XSize || bufY != window.YSize
+	rows := bufY
+	if !sampled {
+		_, blockY := rasterBand.BlockSize()
+		rows = minInt(maxInt(blockY, 1), bufY)
+	}
+	mask := rasterBand.GetMaskBand()
+	allValid := rasterBand.GetMaskFlags()&GMF_AllValid != 0
+	values := make([]float64, bufX*rows)
+	var valid []uint8
+	if !allValid {
+		valid = make([]uint8, bufX*rows)
+	}
+
+	acc := newStatsAccumulator()
+	for y := 0; y < bufY; y += rows {
+		n := minInt(rows, bufY-y)
+		srcY, srcRows := window.YOff+y, n
+		if sampled {
+			srcY, srcRows = window.YOff, window.YSize
+		}
+		err := ReadRegion(
+			rasterBand, window.XOff, srcY, window.XSize, srcRows,
+			values[:bufX*n], bufX, n,
+		)
+		if err != nil {
+			return Statistics{}, err
+		}
+		if !allValid {
+			err := ReadRegion(
+				mask, window.XOff, srcY, window.XSize, srcRows,
+				valid[:bufX*n], bufX, n,
+			)
+			if err != nil {
+				return Statistics{}, err
+			}
+		}
+		for i, v := range values[:bufX*n] {
+			if inside != nil && inside[y*bufX+i] == 0 {
+				continue
+			}
+			if (valid != nil && valid[i] == 0) || math.IsNaN(v) {
+				acc.noData++
+				continue
+			}
+			acc.add(v)
+		}
+	}
+	return acc.statistics(), nil
+}
+
+// geometryWindow returns the pixel window covering the envelope of geom,
+// or the whole band when the dataset is rotated
+func (rasterBand RasterBand) geometryWindow(geom Geometry) (Window, error) {
+	full := Window{XSize: rasterBand.XSize(), YSize: rasterBand.YSize()}
+	transform, err := rasterBand.GetDataset().GeoTransform()
+	if err != nil {
+		return Window{}, err
+	}
+	if transform[2] != 0 || transform[4] != 0 {
+		return full, nil
+	}
+	env := geom.Envelope()
+	x0 := (env.MinX() - transform[0]) / transform[1]
+	x1 := (env.MaxX() - transform[0]) / transform[1]
+	y0 := (env.MinY() - transform[3]) / transform[5]
+	y1 := (env.MaxY() - transform[3]) / transform[5]
+	xOff := maxInt(int(math.Floor(math.Min(x0, x1))), 0)
+	yOff := maxInt(int(math.Floor(math.Min(y0, y1))), 0)
+	xEnd := minInt(int(math.Ceil(math.Max(x0, x1))), full.XSize)
+	yEnd := minInt(int(math.Ceil(math.Max(y0, y1))), full.YSize)
+	if xEnd <= xOff || yEnd <= yOff {
+		return Window{}, fmt.Errorf("geometry does not overlap the raster")
+	}
+	return Window{XOff: xOff, YOff: yOff, XSize: xEnd - xOff, YSize: yEnd - yOff}, nil
+}
+
+// rasterizeWindow burns geom into a bufX x bufY grid covering window and
+// returns 1 for the cells inside it
+func (rasterBand RasterBand) rasterizeWindow(
+	geom Geometry,
+	allTouched bool,
+	window Window,
+	bufX, bufY int,
+) ([]uint8, error) {
+	transform, err := rasterBand.GetDataset().GeoTransform()
+	if err != nil {
+		return nil, err
+	}
+	sx := float64(window.XSize) / float64(bufX)
+	sy := float64(window.YSize) / float64(bufY)
+	x, y := float64(window.XOff), float64(window.YOff)
+	gridTransform := [6]float64{
+		transform[0] + x*transform[1] + y*transform[2], transform[1] * sx, transform[2] * sy,
+		transform[3] + x*transform[4] + y*transform[5], transform[4] * sx, transform[5] * sy,
+	}
+
+	drv, err := GetDriverByName("MEM")
+	if err != nil {
+		return nil, err
+	}
+	grid, err := drv.Create("", bufX, bufY, 1, Byte, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer grid.Close()
+	if err := grid.SetGeoTransform(gridTransform); err != nil {
+		return nil, err
+	}
+	var options []string
+	if allTouched {
+		options = []string{"ALL_TOUCHED=TRUE"}
+	}
+	err = grid.RasterizeGeometries([]int{1}, []Geometry{geom}, []float64{1}, options, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	inside := make([]uint8, bufX*bufY)
+	if err := ReadRegion(grid.RasterBand(1), 0, 0, bufX, bufY, inside, bufX, bufY); err != nil {
+		return nil, err
+	}
+	return inside, nil
+}
+
+// statsAccumulator computes running statistics with Welford's algorithm
+type statsAccumulator struct {
+	min, max, mean, m2 float64
+	count, noData      int
+}
+
+func newStatsAccumulator() *statsAccumulator {
+	return &statsAccumulator{min: math.Inf(1), max: math.Inf(-1)}
+}
+
+func (acc *statsAccumulator) add(v float64) {
+	acc.count++
+	delta := v - acc.mean
+	acc.mean += delta / float64(acc.count)
+	acc.m2 += delta * (v - acc.mean)
+	acc.min = math.Min(acc.min, v)
+	acc.max = math.Max(acc.max, v)
+}
+
+func (acc *statsAccumulator) statistics() Statistics {
+	stats := Statistics{ValidCount: acc.count, NoDataCount: acc.noData}
+	if acc.count == 0 {
+		nan := math.NaN()
+		stats.Min, stats.Max, stats.Mean, stats.StdDev = nan, nan, nan, nan
+		return stats
+	}
+	stats.Min, stats.Max, stats.Mean = acc.min, acc.max, acc.mean
+	stats.StdDev = math.Sqrt(acc.m2 / float64(acc.count))
+	return stats
+}
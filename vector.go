@@ -0,0 +1,138 @@
+package gdal
+
+/*
+#include "go_gdal.h"
+*/
+import "C"
+import (
+	"runtime"
+	"unsafe"
+)
+
+/* -------------------------------------------------------------------- */
+/*      Vector layers of a dataset.                                     */
+/*                                                                      */
+/*      With GDAL 2.0 or later every dataset can hold layers.  With     */
+/*      GDAL 1.x datasets are raster only: these functions return no    */
+/*      layers and DeleteLayer fails with ErrNotSupported.              */
+/* -------------------------------------------------------------------- */
+
+// Fetch the number of layers in this dataset
+func (dataset Dataset) LayerCount() int {
+	defer runtime.KeepAlive(dataset.handle)
+	count := C.goDatasetLayerCount(dataset.cval)
+	return int(count)
+}
+
+// Fetch a layer of this dataset by index
+func (dataset Dataset) LayerByIndex(index int) Layer {
+	defer runtime.KeepAlive(dataset.handle)
+	layer := C.goDatasetLayer(dataset.cval, C.int(index))
+	return Layer{layer, dataset.handle}
+}
+
+// Fetch a layer of this dataset by name
+func (dataset Dataset) LayerByName(name string) Layer {
+	defer runtime.KeepAlive(dataset.handle)
+	cString := C.CString(name)
+	defer C.free(unsafe.Pointer(cString))
+	layer := C.goDatasetLayerByName(dataset.cval, cString)
+	return Layer{layer, dataset.handle}
+}
+
+// Delete the layer from the dataset
+func (dataset Dataset) DeleteLayer(index int) error {
+	defer runtime.KeepAlive(dataset.handle)
+	defer pinErrorState()()
+	return C.goDatasetDeleteLayer(dataset.cval, C.int(index)).Err()
+}
+
+// Create a new layer on the dataset
+func (dataset Dataset) CreateLayer(
+	name string,
+	sr SpatialReference,
+	geomType GeometryType,
+	options []string,
+) Layer {
+	defer runtime.KeepAlive(dataset.handle)
+	defer runtime.KeepAlive(sr.handle)
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+	opts, free := cStringList(options)
+	defer free()
+
+	layer := C.goDatasetCreateLayer(
+		dataset.cval,
+		cName,
+		sr.cval,
+		C.OGRwkbGeometryType(geomType),
+		opts,
+	)
+	return Layer{layer, dataset.handle}
+}
+
+// Duplicate an existing layer
+func (dataset Dataset) CopyLayer(
+	source Layer,
+	name string,
+	options []string,
+) Layer {
+	defer runtime.KeepAlive(dataset.handle)
+	defer runtime.KeepAlive(source.dataSource)
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+	opts, free := cStringList(options)
+	defer free()
+
+	layer := C.goDatasetCopyLayer(dataset.cval, source.cval, cName, opts)
+	return Layer{layer, dataset.handle}
+}
+
+// Test if the dataset has the indicated vector capability
+func (dataset Dataset) TestCapability(capability string) bool {
+	defer runtime.KeepAlive(dataset.handle)
+	cString := C.CString(capability)
+	defer C.free(unsafe.Pointer(cString))
+	val := C.goDatasetTestCapability(dataset.cval, cString)
+	return val != 0
+}
+
+// Execute an SQL statement against the dataset
+func (dataset Dataset) ExecuteSQL(sql string, filter Geometry, dialect string) Layer {
+	defer runtime.KeepAlive(dataset.handle)
+	defer runtime.KeepAlive(filter.handle)
+	cSQL := C.CString(sql)
+	defer C.free(unsafe.Pointer(cSQL))
+	cDialect := C.CString(dialect)
+	defer C.free(unsafe.Pointer(cDialect))
+
+	layer := C.goDatasetExecuteSQL(dataset.cval, cSQL, filter.cval, cDialect)
+	return Layer{layer, dataset.handle}
+}
+
+// Release the results of ExecuteSQL
+func (dataset Dataset) ReleaseResultSet(layer Layer) {
+	defer runtime.KeepAlive(dataset.handle)
+	defer runtime.KeepAlive(layer.dataSource)
+	C.goDatasetReleaseResultSet(dataset.cval, layer.cval)
+}
+
+// View this data source as a dataset. Requires GDAL 2.0 or later, where
+// every data source is a dataset; with GDAL 1.x the result is nil. The
+// dataset is borrowed from the data source.
+func (ds DataSource) Dataset() Dataset {
+	if VERSION_MAJOR < 2 {
+		return Dataset{}
+	}
+	return Dataset{C.GDALDatasetH(ds.cval), borrowHandle(ds.handle)}
+}
+
+// View this driver as a GDAL driver. Requires GDAL 2.0 or later, where
+// raster and vector drivers share one registry; with GDAL 1.x the result
+// is nil.
+func (driver OGRDriver) Driver() Driver {
+	if VERSION_MAJOR < 2 {
+		return Driver{}
+	}
+	return Driver{C.GDALDriverH(driver.cval)}
+}
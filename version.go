@@ -0,0 +1,51 @@
+package gdal
+
+/*
+#include "go_gdal.h"
+*/
+import "C"
+import (
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+/* --------------------------------------------- */
+/* Version detection                             */
+/* --------------------------------------------- */
+
+// The VERSION_* constants give the GDAL headers the package was compiled
+// against, which may differ from the library loaded at run time.
+//
+// Functions added after GDAL 1.x go through shims in go_gdal.c selected on
+// GDAL_VERSION_NUM. Against older headers the shims fail with
+// CPLE_NotSupported, so the functions return errors matching
+// ErrNotSupported.
+
+// Return the release name of the GDAL library loaded at run time, such as "3.4.1"
+func RuntimeVersion() string {
+	key := C.CString("RELEASE_NAME")
+	defer C.free(unsafe.Pointer(key))
+	return C.GoString(C.GDALVersionInfo(key))
+}
+
+// Report whether the GDAL library loaded at run time is at least major.minor
+func CheckVersion(major, minor int) bool {
+	haveMajor, haveMinor := parseVersion(RuntimeVersion())
+	return haveMajor > major || haveMajor == major && haveMinor >= minor
+}
+
+// parseVersion returns the major and minor numbers of a release name such
+// as "2.4.0" or "3.1.0dev"
+func parseVersion(release string) (int, int) {
+	parts := strings.SplitN(release, ".", 3)
+	numbers := [2]int{}
+	for i := 0; i < len(parts) && i < 2; i++ {
+		digits := parts[i]
+		if end := strings.IndexFunc(digits, func(r rune) bool { return r < '0' || r > '9' }); end >= 0 {
+			digits = digits[:end]
+		}
+		numbers[i], _ = strconv.Atoi(digits)
+	}
+	return numbers[0], numbers[1]
+}
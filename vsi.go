@@ -0,0 +1,290 @@
+package gdal
+
+/*
+#include "go_gdal.h"
+*/
+import "C"
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"runtime"
+	"time"
+	"unsafe"
+)
+
+/* --------------------------------------------- */
+/* Virtual file system                           */
+/* --------------------------------------------- */
+
+// Create a /vsimem file holding a copy of data, so that it can be opened
+// like any other file. Remove it with VSIUnlink when done.
+func VSIFileFromMem(filename string, data []byte) error {
+	cFilename := C.CString(filename)
+	defer C.free(unsafe.Pointer(cFilename))
+
+	// GDAL takes ownership of the copy and frees it on unlink. Allocate at
+	// least one byte so that an empty file still gets a valid buffer.
+	buf := C.malloc(C.size_t(maxInt(len(data), 1)))
+	copy(unsafe.Slice((*byte)(buf), len(data)), data)
+	fp := C.VSIFileFromMemBuffer(
+		cFilename, (*C.GByte)(buf), C.vsi_l_offset(len(data)), BoolToCInt(true),
+	)
+	if fp == nil {
+		C.free(buf)
+		return &fs.PathError{Op: "create", Path: filename, Err: ErrFailure}
+	}
+	C.VSIFCloseL(fp)
+	return nil
+}
+
+// Fetch a copy of the contents of a /vsimem file. If unlink is true the
+// file is removed afterwards.
+func VSIGetMemFileBuffer(filename string, unlink bool) ([]byte, error) {
+	cFilename := C.CString(filename)
+	defer C.free(unsafe.Pointer(cFilename))
+
+	var length C.vsi_l_offset
+	buf := C.VSIGetMemFileBuffer(cFilename, &length, BoolToCInt(unlink))
+	if buf == nil {
+		return nil, &fs.PathError{Op: "read", Path: filename, Err: fs.ErrNotExist}
+	}
+	// C.GoBytes takes a C int length, which would truncate large files
+	data := make([]byte, int(length))
+	copy(data, unsafe.Slice((*byte)(unsafe.Pointer(buf)), int(length)))
+	if unlink {
+		// The buffer was handed over with the file
+		C.VSIFree(unsafe.Pointer(buf))
+	}
+	return data, nil
+}
+
+// VSIStatBuf describes a file as reported by VSIStatL
+type VSIStatBuf struct {
+	Size    int64
+	Mode    fs.FileMode
+	ModTime time.Time
+}
+
+// Return true if the file is a directory
+func (stat VSIStatBuf) IsDir() bool {
+	return stat.Mode.IsDir()
+}
+
+// Fetch the size, permissions and modification time of a file
+func VSIStatL(filename string) (VSIStatBuf, error) {
+	cFilename := C.CString(filename)
+	defer C.free(unsafe.Pointer(cFilename))
+
+	var size, mtime C.longlong
+	var mode, isDir C.int
+	if C.goVSIStat(cFilename, &size, &mtime, &mode, &isDir) != 0 {
+		return VSIStatBuf{}, &fs.PathError{Op: "stat", Path: filename, Err: fs.ErrNotExist}
+	}
+	stat := VSIStatBuf{
+		Size:    int64(size),
+		Mode:    fs.FileMode(mode),
+		ModTime: time.Unix(int64(mtime), 0),
+	}
+	if isDir != 0 {
+		stat.Mode |= fs.ModeDir
+	}
+	return stat, nil
+}
+
+// List the names of the entries of a directory
+func VSIReadDir(path string) ([]string, error) {
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+
+	list := C.VSIReadDir(cPath)
+	if list == nil {
+		// NULL is returned for both missing and empty directories
+		if stat, err := VSIStatL(path); err != nil || !stat.IsDir() {
+			return nil, &fs.PathError{Op: "readdir", Path: path, Err: fs.ErrNotExist}
+		}
+		return []string{}, nil
+	}
+	defer C.CSLDestroy(list)
+	return goStringList(list), nil
+}
+
+// Delete a file
+func VSIUnlink(filename string) error {
+	cFilename := C.CString(filename)
+	defer C.free(unsafe.Pointer(cFilename))
+
+	result, errno := C.VSIUnlink(cFilename)
+	if result != 0 {
+		return vsiPathError("unlink", filename, errno)
+	}
+	return nil
+}
+
+// Create a directory with the given permissions
+func VSIMkdir(path string, perm fs.FileMode) error {
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+
+	result, errno := C.VSIMkdir(cPath, C.long(perm.Perm()))
+	if result != 0 {
+		return vsiPathError("mkdir", path, errno)
+	}
+	return nil
+}
+
+// vsiPathError wraps the errno left by a failed VSI call, which is not
+// set by every file system handler
+func vsiPathError(op, path string, errno error) error {
+	if errno == nil {
+		errno = ErrFailure
+	}
+	return &fs.PathError{Op: op, Path: path, Err: errno}
+}
+
+/* --------------------------------------------- */
+/* Virtual files                                 */
+/* --------------------------------------------- */
+
+// VSIFile is an open file of the GDAL virtual file system. It implements
+// io.Reader, io.Writer, io.Seeker and io.Closer.
+type VSIFile struct {
+	cval *C.VSILFILE
+	name string
+	*handle
+}
+
+func newVSIFile(fp *C.VSILFILE, name string) VSIFile {
+	return VSIFile{fp, name, ownHandle("VSIFile", fp, func(fp *C.VSILFILE) error {
+		if C.VSIFCloseL(fp) != 0 {
+			return &fs.PathError{Op: "close", Path: name, Err: ErrFailure}
+		}
+		return nil
+	})}
+}
+
+// Open a file with an fopen() style access string such as "rb", "wb" or
+// "r+b"
+func VSIFOpenL(filename, access string) (VSIFile, error) {
+	cFilename := C.CString(filename)
+	defer C.free(unsafe.Pointer(cFilename))
+	cAccess := C.CString(access)
+	defer C.free(unsafe.Pointer(cAccess))
+
+	fp, errno := C.VSIFOpenL(cFilename, cAccess)
+	if fp == nil {
+		return VSIFile{}, vsiPathError("open", filename, errno)
+	}
+	return newVSIFile(fp, filename), nil
+}
+
+// Open a file for reading
+func VSIOpen(filename string) (VSIFile, error) {
+	return VSIFOpenL(filename, "rb")
+}
+
+// Create or truncate a file for writing
+func VSICreate(filename string) (VSIFile, error) {
+	return VSIFOpenL(filename, "wb")
+}
+
+// Return the name the file was opened with
+func (file VSIFile) Name() string {
+	return file.name
+}
+
+// Return true if this file wraps a NULL handle or has been closed
+func (file VSIFile) IsNil() bool {
+	return file.cval == nil || file.isClosed()
+}
+
+// Close the file. Closing it again is a no-op.
+func (file VSIFile) Close() error {
+	return file.close()
+}
+
+func (file VSIFile) check(op string) error {
+	if file.IsNil() {
+		return &fs.PathError{Op: op, Path: file.name, Err: fs.ErrClosed}
+	}
+	return nil
+}
+
+// Read up to len(p) bytes, returning io.EOF at the end of the file
+func (file VSIFile) Read(p []byte) (int, error) {
+	defer runtime.KeepAlive(file.handle)
+	if err := file.check("read"); err != nil {
+		return 0, err
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	n := int(C.VSIFReadL(unsafe.Pointer(&p[0]), 1, C.size_t(len(p)), file.cval))
+	if n < len(p) {
+		if C.VSIFEofL(file.cval) != 0 {
+			if n == 0 {
+				return 0, io.EOF
+			}
+			return n, nil
+		}
+		return n, &fs.PathError{Op: "read", Path: file.name, Err: ErrFailure}
+	}
+	return n, nil
+}
+
+// Write len(p) bytes
+func (file VSIFile) Write(p []byte) (int, error) {
+	defer runtime.KeepAlive(file.handle)
+	if err := file.check("write"); err != nil {
+		return 0, err
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	n := int(C.VSIFWriteL(unsafe.Pointer(&p[0]), 1, C.size_t(len(p)), file.cval))
+	if n < len(p) {
+		return n, &fs.PathError{Op: "write", Path: file.name, Err: io.ErrShortWrite}
+	}
+	return n, nil
+}
+
+// Set the offset for the next Read or Write, returning the new offset
+func (file VSIFile) Seek(offset int64, whence int) (int64, error) {
+	defer runtime.KeepAlive(file.handle)
+	if err := file.check("seek"); err != nil {
+		return 0, err
+	}
+	var base int64
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		base = int64(C.VSIFTellL(file.cval))
+	case io.SeekEnd:
+		if C.VSIFSeekL(file.cval, 0, C.SEEK_END) != 0 {
+			return 0, &fs.PathError{Op: "seek", Path: file.name, Err: ErrFailure}
+		}
+		base = int64(C.VSIFTellL(file.cval))
+	default:
+		return 0, &fs.PathError{Op: "seek", Path: file.name, Err: errors.New("invalid whence")}
+	}
+	position := base + offset
+	if position < 0 {
+		return 0, &fs.PathError{Op: "seek", Path: file.name, Err: errors.New("negative position")}
+	}
+	if C.VSIFSeekL(file.cval, C.vsi_l_offset(position), C.SEEK_SET) != 0 {
+		return 0, &fs.PathError{Op: "seek", Path: file.name, Err: ErrFailure}
+	}
+	return position, nil
+}
+
+// Flush buffered writes
+func (file VSIFile) Flush() error {
+	defer runtime.KeepAlive(file.handle)
+	if err := file.check("flush"); err != nil {
+		return err
+	}
+	if C.VSIFFlushL(file.cval) != 0 {
+		return &fs.PathError{Op: "flush", Path: file.name, Err: ErrFailure}
+	}
+	return nil
+}
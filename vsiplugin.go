@@ -0,0 +1,265 @@
+package gdal
+
+/*
+#include "go_gdal.h"
+*/
+import "C"
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"runtime/cgo"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+/* --------------------------------------------- */
+/* Go file systems                               */
+/* --------------------------------------------- */
+
+var vsiHandlers struct {
+	sync.Mutex
+	prefixes map[string]cgo.Handle
+}
+
+// vsiHandler is the user data of a VSI file system backed by an fs.FS
+type vsiHandler struct {
+	prefix string
+	fsys   fs.FS
+}
+
+// vsiHandlerFile is a file opened through a vsiHandler
+type vsiHandlerFile struct {
+	mu   sync.Mutex
+	name string
+	file fs.File
+	size int64
+	pos  int64
+	eof  bool
+}
+
+// RegisterVSIHandler makes the files of fsys readable by GDAL under prefix,
+// which must start and end with a slash, e.g. "/vsigo/". The file
+// "/vsigo/dir/a.tif" is then read as "dir/a.tif" from fsys. Reads are
+// served with ReadAt when the files implement io.ReaderAt, so GDAL only
+// fetches the byte ranges it needs, and with Seek and Read otherwise.
+//
+// Handlers are read only and stay registered for the life of the process.
+// Requires GDAL 3.0.
+func RegisterVSIHandler(prefix string, fsys fs.FS) error {
+	if !strings.HasPrefix(prefix, "/") || !strings.HasSuffix(prefix, "/") {
+		return fmt.Errorf("VSI prefix %q must start and end with /", prefix)
+	}
+
+	vsiHandlers.Lock()
+	defer vsiHandlers.Unlock()
+	if _, ok := vsiHandlers.prefixes[prefix]; ok {
+		return fmt.Errorf("VSI prefix %s is already registered", prefix)
+	}
+
+	cPrefix := C.CString(prefix)
+	defer C.free(unsafe.Pointer(cPrefix))
+	handle := cgo.NewHandle(&vsiHandler{prefix, fsys})
+
+	defer pinErrorState()()
+	if C.goVSIInstallPluginHandler(cPrefix, C.uintptr_t(handle)) != 0 {
+		handle.Delete()
+		return lastError(CE_Failure)
+	}
+	if vsiHandlers.prefixes == nil {
+		vsiHandlers.prefixes = make(map[string]cgo.Handle)
+	}
+	vsiHandlers.prefixes[prefix] = handle
+	return nil
+}
+
+// path maps a GDAL file name to a name in the fs.FS
+func (h *vsiHandler) path(filename string) (string, bool) {
+	name, ok := strings.CutPrefix(filename, h.prefix)
+	if !ok {
+		// The root may be given without its trailing slash
+		if filename+"/" != h.prefix {
+			return "", false
+		}
+	}
+	name = strings.Trim(name, "/")
+	if name == "" {
+		name = "."
+	}
+	return name, fs.ValidPath(name)
+}
+
+// reportVSIError raises a GDAL error for a failure of the Go file system.
+// Missing files are not reported since GDAL probes for many optional
+// sibling files.
+func reportVSIError(err error) {
+	if err == nil || errors.Is(err, fs.ErrNotExist) {
+		return
+	}
+	msg := C.CString(err.Error())
+	defer C.free(unsafe.Pointer(msg))
+	C.goCPLError(C.CPLErr(CE_Failure), C.int(CPLE_FileIO), msg)
+}
+
+//export goVSIHandlerStat
+func goVSIHandlerStat(
+	fsys C.uintptr_t,
+	filename *C.char,
+	size, mtime *C.longlong,
+	isDir *C.int,
+) C.int {
+	h := cgo.Handle(fsys).Value().(*vsiHandler)
+	name, ok := h.path(C.GoString(filename))
+	if !ok {
+		return -1
+	}
+	info, err := fs.Stat(h.fsys, name)
+	if err != nil {
+		reportVSIError(err)
+		return -1
+	}
+	*size = C.longlong(info.Size())
+	*mtime = C.longlong(info.ModTime().Unix())
+	*isDir = BoolToCInt(info.IsDir())
+	return 0
+}
+
+//export goVSIHandlerReadDir
+func goVSIHandlerReadDir(fsys C.uintptr_t, dirname *C.char, maxFiles C.int) **C.char {
+	h := cgo.Handle(fsys).Value().(*vsiHandler)
+	name, ok := h.path(C.GoString(dirname))
+	if !ok {
+		return nil
+	}
+	entries, err := fs.ReadDir(h.fsys, name)
+	if err != nil {
+		reportVSIError(err)
+		return nil
+	}
+	var list **C.char
+	for i, entry := range entries {
+		if maxFiles > 0 && i >= int(maxFiles) {
+			break
+		}
+		cName := C.CString(entry.Name())
+		list = C.CSLAddString(list, cName)
+		C.free(unsafe.Pointer(cName))
+	}
+	return list
+}
+
+//export goVSIHandlerOpen
+func goVSIHandlerOpen(fsys C.uintptr_t, filename, access *C.char) C.uintptr_t {
+	h := cgo.Handle(fsys).Value().(*vsiHandler)
+	name, ok := h.path(C.GoString(filename))
+	if !ok || strings.ContainsAny(C.GoString(access), "wa+") {
+		return 0
+	}
+	file, err := h.fsys.Open(name)
+	if err != nil {
+		reportVSIError(err)
+		return 0
+	}
+	info, err := file.Stat()
+	if err == nil && info.IsDir() {
+		err = fmt.Errorf("%s is a directory", name)
+	}
+	if err == nil {
+		_, readerAt := file.(io.ReaderAt)
+		_, seeker := file.(io.Seeker)
+		if !readerAt && !seeker {
+			err = fmt.Errorf("%s implements neither io.ReaderAt nor io.Seeker", name)
+		}
+	}
+	if err != nil {
+		file.Close()
+		reportVSIError(err)
+		return 0
+	}
+	return C.uintptr_t(cgo.NewHandle(&vsiHandlerFile{
+		name: name,
+		file: file,
+		size: info.Size(),
+	}))
+}
+
+//export goVSIFileTell
+func goVSIFileTell(file C.uintptr_t) C.vsi_l_offset {
+	f := cgo.Handle(file).Value().(*vsiHandlerFile)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return C.vsi_l_offset(f.pos)
+}
+
+//export goVSIFileSeek
+func goVSIFileSeek(file C.uintptr_t, offset C.vsi_l_offset, whence C.int) C.int {
+	f := cgo.Handle(file).Value().(*vsiHandlerFile)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch whence {
+	case C.SEEK_SET:
+		f.pos = int64(offset)
+	case C.SEEK_CUR:
+		f.pos += int64(offset)
+	case C.SEEK_END:
+		f.pos = f.size + int64(offset)
+	default:
+		return -1
+	}
+	f.eof = false
+	return 0
+}
+
+//export goVSIFileRead
+func goVSIFileRead(file C.uintptr_t, buffer unsafe.Pointer, size, count C.size_t) C.size_t {
+	f := cgo.Handle(file).Value().(*vsiHandlerFile)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if size == 0 || count == 0 {
+		return 0
+	}
+	if size > C.size_t(math.MaxInt)/count {
+		reportVSIError(fmt.Errorf("reading %s: %d items of %d bytes overflow", f.name, count, size))
+		return 0
+	}
+	buf := unsafe.Slice((*byte)(buffer), int(size*count))
+
+	var n int
+	var err error
+	if readerAt, ok := f.file.(io.ReaderAt); ok {
+		n, err = readerAt.ReadAt(buf, f.pos)
+	} else if _, err = f.file.(io.Seeker).Seek(f.pos, io.SeekStart); err == nil {
+		n, err = io.ReadFull(f.file, buf)
+	}
+	f.pos += int64(n)
+	switch {
+	case err == io.EOF || err == io.ErrUnexpectedEOF:
+		f.eof = true
+	case err != nil:
+		reportVSIError(fmt.Errorf("reading %s: %w", f.name, err))
+	}
+	return C.size_t(n) / size
+}
+
+//export goVSIFileEOF
+func goVSIFileEOF(file C.uintptr_t) C.int {
+	f := cgo.Handle(file).Value().(*vsiHandlerFile)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return BoolToCInt(f.eof)
+}
+
+//export goVSIFileClose
+func goVSIFileClose(file C.uintptr_t) C.int {
+	handle := cgo.Handle(file)
+	f := handle.Value().(*vsiHandlerFile)
+	handle.Delete()
+	if err := f.file.Close(); err != nil {
+		reportVSIError(err)
+		return -1
+	}
+	return 0
+}